@@ -1,71 +1,512 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
 	"strconv"
-	"sync"
+	"strings"
+	"syscall"
+	"time"
 
 	http2 "github.com/vcsfrl/github-tool-finder/http"
 
 	"github.com/vcsfrl/github-tool-finder/search"
 )
 
+const (
+	exitError = iota + 1
+	exitUsage
+	exitUnauthorized
+	exitForbidden
+	exitRateLimited
+	exitNotFound
+	exitServerError
+)
+
+const exitInterrupted = 130
+
+type config struct {
+	query        string
+	total        int
+	tokens       []string
+	endpoint     *url.URL
+	maxAttempts  int
+	timeout      time.Duration
+	deadline     time.Duration
+	app          *appConfig
+	cache        *cacheConfig
+	record       string
+	replay       string
+	slice        string
+	verbose      bool
+	fields       []*search.Field
+	enrichers    []search.Enricher
+	enrichBatch  int
+	historyDepth int
+	sampleSize   int
+	null         string
+}
+
+type cacheConfig struct {
+	directory string
+	ttl       time.Duration
+	offline   bool
+	clear     bool
+}
+
+type appConfig struct {
+	id             string
+	installationID string
+	keyFile        string
+	apiURL         string
+}
+
 func main() {
-	query, total, token := getArguments()
+	cfg := getArguments()
+
+	ctx, cancel := newContext(cfg)
+	defer cancel()
 
 	transport := make(chan *search.Repository, 1024*1024)
-	client := http2.NewAuthenticationClientV4(http.DefaultClient, token)
-	reader := search.NewRepositoryReader(query, total, transport, client)
-	writer := search.NewCsvWriter(transport, os.Stdout)
+	client, recorder := newClient(cfg)
+	reader := search.NewRepositoryReader(cfg.query, cfg.total, transport, client)
+	reader.SelectFields(cfg.fields)
+	useSlicer(cfg, reader)
+	output, enrichErr := startEnricher(ctx, cancel, cfg, client, transport)
+	writer := search.NewCsvWriter(output, os.Stdout)
+	writer.SelectFields(cfg.fields)
+	writer.SetNullValue(cfg.null)
 
-	var wg sync.WaitGroup
+	readErr := make(chan error, 1)
+	go func() {
+		readErr <- reader.Handle(ctx)
+	}()
 
-	wg.Add(1)
+	// The writer is not stopped by the cancellation, only flushed: it drains the
+	// output channel, which the reader (or the enricher) closes when it stops, so
+	// every fetched repository is written.
+	writeErr := writer.Handle(ctx)
+	err := <-readErr
+	saveRecording(recorder)
+
+	// A failed enrichment cancels the reader, its error is the cause.
+	if enrichmentErr := <-enrichErr; nil != enrichmentErr && (nil == err || errors.Is(err, context.Canceled)) {
+		err = enrichmentErr
+	}
+
+	printSummary(reader, writer, err)
+
+	if nil != writeErr {
+		log.Fatal(writeErr)
+	}
+
+	if nil != err {
+		exitOnReadError(err)
+	}
+}
+
+// startEnricher enriches the repositories of the input channel when enrichment
+// fields are selected and returns the channel of the enriched repositories. A
+// failed enrichment cancels the run.
+func startEnricher(ctx context.Context, cancel context.CancelFunc, cfg *config, client http2.Client, input chan *search.Repository) (chan *search.Repository, chan error) {
+	enrichErr := make(chan error, 1)
+	if len(cfg.enrichers) == 0 {
+		enrichErr <- nil
+		return input, enrichErr
+	}
+
+	output := make(chan *search.Repository, 1024)
+	enricher := search.NewRepositoryEnricher(input, output, client, cfg.enrichers)
+	enricher.SetBatchSize(cfg.enrichBatch)
+	enricher.SetCancel(cancel)
 
 	go func() {
-		err := reader.Handle()
-		if nil != err {
-			log.Fatal(err)
+		enrichErr <- enricher.Handle(ctx)
+	}()
+
+	return output, enrichErr
+}
+
+// newContext returns the context of the run: it is cancelled by SIGINT or SIGTERM
+// and expires after the run deadline. A second signal exits immediately.
+func newContext(cfg *config) (context.Context, context.CancelFunc) {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	if cfg.deadline > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), cfg.deadline)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
 		}
 
-		wg.Done()
+		<-signals
+		os.Exit(exitInterrupted)
 	}()
 
-	if err := writer.Handle(); nil != err {
-		log.Fatal(err)
+	return ctx, cancel
+}
+
+// printSummary reports the number of written repositories on STDERR and, when the
+// run stopped early, the cursor of the last page read.
+func printSummary(reader *search.RepositoryReader, writer *search.CsvWriter, err error) {
+	fmt.Fprintf(os.Stderr, "Wrote %d repositories", writer.Written())
+
+	if nil != err && reader.Cursor() != "" {
+		fmt.Fprintf(os.Stderr, ", stopped after cursor %s", reader.Cursor())
+	}
+
+	fmt.Fprintln(os.Stderr, ".")
+}
+
+// exitOnReadError explains the error and exits with a code distinct for each kind
+// of failure.
+func exitOnReadError(err error) {
+	var (
+		rateLimitErr *search.RateLimitError
+		code         = exitError
+		hint         string
+	)
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		code, hint = exitInterrupted, "The search was interrupted."
+	case errors.Is(err, context.DeadlineExceeded):
+		code, hint = exitError, "The search did not finish within the run deadline. Raise the -deadline option."
+	case errors.As(err, &rateLimitErr):
+		code, hint = exitRateLimited, "The github rate limit is exceeded. Try again later or use several tokens (GH_TOKEN=token1,token2)."
+		if !rateLimitErr.Reset.IsZero() {
+			hint = fmt.Sprintf("The github rate limit is exceeded until %s. Try again later or use several tokens (GH_TOKEN=token1,token2).", rateLimitErr.Reset.Local().Format(time.RFC1123))
+		}
+	case errors.Is(err, search.ErrUnauthorized):
+		code, hint = exitUnauthorized, "Github rejected the credentials. Check GH_TOKEN or the app options."
+	case errors.Is(err, search.ErrForbidden):
+		code, hint = exitForbidden, "Github refused the request. Check the permissions of the token."
+	case errors.Is(err, search.ErrNotFound):
+		code, hint = exitNotFound, "The GraphQL endpoint was not found. Check the -host option or GH_HOST."
+	case errors.Is(err, search.ErrServer):
+		code, hint = exitServerError, "Github is not available. Try again later or raise -max-attempts."
+	}
+
+	log.Println(err)
+
+	if hint != "" {
+		fmt.Fprintln(os.Stderr, hint)
+	}
+
+	os.Exit(code)
+}
+
+// saveRecording writes the recorded cassette, also when the search failed, so the
+// failing session can be replayed.
+func saveRecording(recorder *http2.CassetteClient) {
+	if nil == recorder {
+		return
+	}
+
+	if err := recorder.Close(); nil != err {
+		log.Println(err)
+	}
+}
+
+// githubLaunch is the earliest creation date of a github repository.
+var githubLaunch = time.Date(2007, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+func useSlicer(cfg *config, reader *search.RepositoryReader) {
+	var logger *log.Logger
+	if cfg.verbose {
+		logger = log.New(os.Stderr, "", log.LstdFlags)
+	}
+
+	// The slices end at the start of the day, so the queries of two runs on the same
+	// day match the cache and the recorded cassettes. The last slice is open-ended.
+	end := time.Now().UTC().Truncate(24 * time.Hour)
+
+	switch cfg.slice {
+	case "created":
+		reader.UseSlicer(search.NewDateSlicer(githubLaunch, end, logger))
+	case "stars":
+		reader.UseSlicer(search.NewStarSlicer(search.NewDateSlicer(githubLaunch, end, logger), logger))
+	}
+}
+
+func newClient(cfg *config) (http2.Client, *http2.CassetteClient) {
+	if cfg.replay != "" {
+		cassette, err := http2.LoadCassette(cfg.replay)
+		exitOnError(err)
+
+		return http2.NewAuthenticationClientV4(http2.NewReplayingClient(cassette), "", http2.WithEndpoint(cfg.endpoint)), nil
+	}
+
+	var (
+		token  string
+		client http2.Client
+		retry  = http2.NewRetryClient(&http.Client{Timeout: cfg.timeout}, cfg.maxAttempts, time.Second, time.Minute)
+	)
+
+	switch {
+	case cfg.app.id != "":
+		client = newAppClient(cfg, http2.NewRateLimitClient(retry))
+	case len(cfg.tokens) > 1:
+		client = http2.NewTokenPoolClient(retry, cfg.tokens)
+	case len(cfg.tokens) == 1:
+		client, token = http2.NewRateLimitClient(retry), cfg.tokens[0]
+	default:
+		client = http2.NewRateLimitClient(retry)
+	}
+
+	if cfg.cache.directory != "" {
+		client = http2.NewCacheClient(client, cfg.cache.directory, cfg.cache.ttl, cfg.cache.offline)
+	}
+
+	if cfg.record == "" {
+		return http2.NewAuthenticationClientV4(client, token, http2.WithEndpoint(cfg.endpoint)), nil
 	}
-	wg.Wait()
+
+	recorder := http2.NewRecordingClient(client, cfg.record)
+
+	return http2.NewAuthenticationClientV4(recorder, token, http2.WithEndpoint(cfg.endpoint)), recorder
 }
 
-func getArguments() (string, int, string) {
-	argLength := len(os.Args[1:])
-	if argLength != 2 {
-		fmt.Fprintln(os.Stderr, usage())
-		os.Exit(1)
+func newAppClient(cfg *config, inner http2.Client) http2.Client {
+	key, err := http2.LoadPrivateKey(cfg.app.keyFile)
+	exitOnError(err)
+
+	apiURL := http2.RESTEndpoint(cfg.endpoint)
+	if cfg.app.apiURL != "" {
+		apiURL, err = url.Parse(cfg.app.apiURL)
+		exitOnError(err)
+	}
+
+	return http2.NewAppAuthenticationClient(inner, cfg.app.id, cfg.app.installationID, key, apiURL)
+}
+
+func getArguments() *config {
+	cfg := &config{app: &appConfig{}, cache: &cacheConfig{}}
+
+	var host, tokenFile, fields, enrich string
+
+	flag.Usage = printUsage
+	flag.StringVar(&host, "host", os.Getenv("GH_HOST"), "github host or GraphQL endpoint URL, e.g. github.example.com (default api.github.com)")
+	flag.StringVar(&tokenFile, "token-file", "", "file with github tokens, one per line, used instead of GH_TOKEN")
+	flag.StringVar(&cfg.app.id, "app-id", "", "github app id, authenticates as an app installation instead of using GH_TOKEN")
+	flag.StringVar(&cfg.app.installationID, "app-installation-id", "", "github app installation id")
+	flag.StringVar(&cfg.app.keyFile, "app-key", "", "github app private key file (PEM)")
+	flag.StringVar(&cfg.app.apiURL, "app-api-url", "", "REST API URL used to exchange the app installation token (default derived from -host)")
+	flag.IntVar(&cfg.maxAttempts, "max-attempts", 5, "maximum number of attempts for a request failing with a transient error")
+	flag.DurationVar(&cfg.timeout, "timeout", time.Minute, "timeout of a single request, 0 disables it")
+	flag.DurationVar(&cfg.deadline, "deadline", 0, "maximum duration of the whole run, 0 disables it")
+	flag.StringVar(&cfg.cache.directory, "cache-dir", "", "directory where responses are cached, caching is disabled when empty")
+	flag.DurationVar(&cfg.cache.ttl, "cache-ttl", time.Hour, "time a cached response is served without asking github")
+	flag.BoolVar(&cfg.cache.offline, "offline", false, "serve responses only from the cache, fail on a cache miss")
+	flag.BoolVar(&cfg.cache.clear, "cache-clear", false, "remove every cached response from -cache-dir and exit")
+	flag.StringVar(&cfg.record, "record", "", "record the requests and responses of the session to a cassette file (authorization is redacted)")
+	flag.StringVar(&cfg.replay, "replay", "", "replay the responses from a cassette file recorded with -record, without network access")
+	flag.StringVar(&cfg.slice, "slice", "", "split queries matching more than 1000 repositories to fetch past the search cap: created, stars")
+	flag.StringVar(&fields, "fields", os.Getenv("GH_FIELDS"), "comma separated repository fields to fetch, written as columns in the given order: "+strings.Join(search.FieldNames(newEnrichers(cfg)...), ", ")+" (default all fields of the search; enrichment fields, see -enrich, can be listed too)")
+	flag.StringVar(&enrich, "enrich", "", "comma separated enrichments adding their columns, fetched with an extra query per batch of repositories: "+enrichmentNames(cfg))
+	flag.IntVar(&cfg.historyDepth, "history-depth", search.DefaultHistoryDepth, fmt.Sprintf("number of latest commits read for the contributors enrichment, at most %d", search.MaxHistoryDepth))
+	flag.IntVar(&cfg.sampleSize, "sample-size", search.DefaultSampleSize, fmt.Sprintf("number of latest issues, and pull requests, sampled for the responsiveness enrichment, at most %d", search.MaxSampleSize))
+	flag.IntVar(&cfg.enrichBatch, "enrich-batch", search.DefaultBatchSize, fmt.Sprintf("number of repositories enriched with one query, at most %d", search.MaxBatchSize))
+	flag.StringVar(&cfg.null, "null", "", "text written for values github returns as null, e.g. the license of a repository without one")
+	flag.BoolVar(&cfg.verbose, "verbose", false, "log progress, e.g. the query slices, to STDERR")
+	flag.Parse()
+
+	if cfg.slice != "" && cfg.slice != "created" && cfg.slice != "stars" {
+		fmt.Fprintf(os.Stderr, "Unknown slicing strategy %q (option: -slice).\n", cfg.slice)
+		os.Exit(exitUsage)
+	}
+
+	if cfg.enrichBatch < 1 || cfg.enrichBatch > search.MaxBatchSize {
+		fmt.Fprintf(os.Stderr, "Invalid enrichment batch size %d, expected 1 to %d (option: -enrich-batch).\n", cfg.enrichBatch, search.MaxBatchSize)
+		os.Exit(exitUsage)
+	}
+
+	if cfg.historyDepth < 1 || cfg.historyDepth > search.MaxHistoryDepth {
+		fmt.Fprintf(os.Stderr, "Invalid history depth %d, expected 1 to %d (option: -history-depth).\n", cfg.historyDepth, search.MaxHistoryDepth)
+		os.Exit(exitUsage)
 	}
 
-	token, ok := os.LookupEnv("GH_TOKEN")
-	if !ok {
-		fmt.Fprintln(os.Stderr, "Please specify a github token (environment variable: GH_TOKEN).")
-		os.Exit(1)
+	if cfg.sampleSize < 1 || cfg.sampleSize > search.MaxSampleSize {
+		fmt.Fprintf(os.Stderr, "Invalid sample size %d, expected 1 to %d (option: -sample-size).\n", cfg.sampleSize, search.MaxSampleSize)
+		os.Exit(exitUsage)
+	}
+
+	enrichers := newEnrichers(cfg)
+
+	var err error
+	cfg.fields, err = search.ParseFields(fields, enrichers...)
+	exitOnUsageError(err)
+
+	cfg.fields, err = appendEnrichmentFields(cfg.fields, enrich, enrichers)
+	exitOnUsageError(err)
+	cfg.enrichers = search.EnrichersOf(cfg.fields, enrichers)
+
+	if cfg.cache.directory == "" && (cfg.cache.offline || cfg.cache.clear) {
+		fmt.Fprintln(os.Stderr, "Please specify the cache directory (option: -cache-dir).")
+		os.Exit(exitUsage)
+	}
+
+	if cfg.cache.clear {
+		exitOnError(http2.ClearCache(cfg.cache.directory))
+		os.Exit(0)
+	}
+
+	if flag.NArg() < 1 || flag.NArg() > 2 {
+		flag.Usage()
+		os.Exit(exitUsage)
+	}
+
+	tokens, err := readTokens(tokenFile)
+	exitOnError(err)
+
+	if cfg.app.id != "" && (cfg.app.installationID == "" || cfg.app.keyFile == "") {
+		fmt.Fprintln(os.Stderr, "Please specify the app installation id and private key (options: -app-installation-id, -app-key).")
+		os.Exit(exitUsage)
+	}
+
+	if cfg.app.id == "" && len(tokens) == 0 && !cfg.cache.offline && cfg.replay == "" {
+		fmt.Fprintln(os.Stderr, "Please specify a github token (environment variable: GH_TOKEN or option: -token-file).")
+		os.Exit(exitUsage)
+	}
+
+	total, err := parseTotal(flag.Arg(1))
+	exitOnError(err)
+
+	endpoint, err := http2.ParseEndpoint(host)
+	exitOnError(err)
+
+	cfg.query = flag.Arg(0)
+	cfg.total = total
+	cfg.tokens = tokens
+	cfg.endpoint = endpoint
+
+	return cfg
+}
+
+// parseTotal reads the maximum number of repositories to fetch; an omitted total
+// or 0 fetches every repository matching the query.
+func parseTotal(value string) (int, error) {
+	if value == "" {
+		return 0, nil
 	}
 
-	total, err := strconv.Atoi(os.Args[2])
+	total, err := strconv.Atoi(value)
+	if nil == err && total < 0 {
+		err = fmt.Errorf("invalid total %d, expected 0 or more", total)
+	}
+
+	return total, err
+}
+
+// newEnrichers returns the available enrichers, set up with the enrichment options
+// of the configuration. The commit windows end at the start of the day, so two runs
+// on the same day send the same query.
+func newEnrichers(cfg *config) []search.Enricher {
+	return []search.Enricher{
+		search.NewLanguagesEnricher(),
+		search.NewCommitsEnricher(time.Now().UTC().Truncate(24 * time.Hour)),
+		search.NewReleasesEnricher(time.Now()),
+		search.NewContributorsEnricher(cfg.historyDepth),
+		search.NewResponsivenessEnricher(cfg.sampleSize),
+		search.NewHealthEnricher(),
+	}
+}
+
+func enrichmentNames(cfg *config) string {
+	var names []string
+	for _, enricher := range newEnrichers(cfg) {
+		names = append(names, enricher.Name())
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// appendEnrichmentFields adds the fields of the named enrichers which are not
+// selected yet.
+func appendEnrichmentFields(fields []*search.Field, names string, enrichers []search.Enricher) ([]*search.Field, error) {
+	selected := map[*search.Field]bool{}
+	for _, field := range fields {
+		selected[field] = true
+	}
+
+	for _, name := range strings.Split(names, ",") {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+
+		enricher := search.FindEnricher(strings.TrimSpace(name), enrichers)
+		if nil == enricher {
+			return nil, fmt.Errorf("unknown enrichment %q (option: -enrich)", strings.TrimSpace(name))
+		}
+
+		for _, field := range enricher.Fields() {
+			if !selected[field] {
+				selected[field] = true
+				fields = append(fields, field)
+			}
+		}
+	}
+
+	return fields, nil
+}
+
+func readTokens(tokenFile string) ([]string, error) {
+	if tokenFile == "" {
+		return http2.ParseTokens(os.Getenv("GH_TOKEN")), nil
+	}
+
+	content, err := ioutil.ReadFile(tokenFile)
+	if nil != err {
+		return nil, err
+	}
+
+	return http2.ParseTokens(string(content)), nil
+}
+
+func exitOnError(err error) {
 	if nil != err {
 		fmt.Fprintln(os.Stderr, err.Error())
-		os.Exit(1)
+		os.Exit(exitError)
+	}
+}
+
+func exitOnUsageError(err error) {
+	if nil != err {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitUsage)
 	}
+}
 
-	return os.Args[1], total, token
+func printUsage() {
+	fmt.Fprintln(os.Stderr, usage())
+	flag.PrintDefaults()
+	fmt.Fprintln(os.Stderr)
 }
 
 func usage() string {
 	return fmt.Sprintf(`
 Usage:
- search [query] [total]
+ search [options] [query] [total]
 
-`)
+Options:`)
 }
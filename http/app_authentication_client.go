@@ -0,0 +1,182 @@
+package http
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+)
+
+var ErrAppAuthentication = errors.New("github app authentication error")
+
+const (
+	jwtLifetime        = 9 * time.Minute
+	jwtClockDrift      = time.Minute
+	tokenRefreshBefore = 5 * time.Minute
+)
+
+// LoadPrivateKey reads a GitHub App private key from a PEM file (PKCS#1 or PKCS#8).
+func LoadPrivateKey(file string) (*rsa.PrivateKey, error) {
+	content, err := ioutil.ReadFile(file)
+	if nil != err {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(content)
+	if nil == block {
+		return nil, fmt.Errorf("%s: no PEM data: %w", file, ErrAppAuthentication)
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); nil == err {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if nil != err {
+		return nil, fmt.Errorf("%s: %s: %w", file, err.Error(), ErrAppAuthentication)
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an RSA key: %w", file, ErrAppAuthentication)
+	}
+
+	return key, nil
+}
+
+func NewAppAuthenticationClient(inner Client, appID string, installationID string, key *rsa.PrivateKey, apiURL *url.URL) *AppAuthenticationClient {
+	tokenURL := *apiURL
+	tokenURL.Path = path.Join("/", apiURL.Path, "app/installations", installationID, "access_tokens")
+
+	return &AppAuthenticationClient{
+		inner:    inner,
+		appID:    appID,
+		key:      key,
+		tokenURL: &tokenURL,
+		now:      time.Now,
+	}
+}
+
+// AppAuthenticationClient authenticates requests as a GitHub App installation. The
+// installation token is exchanged with a JWT signed by the App private key, cached,
+// and refreshed before it expires.
+type AppAuthenticationClient struct {
+	inner     Client
+	appID     string
+	key       *rsa.PrivateKey
+	tokenURL  *url.URL
+	mutex     sync.Mutex
+	token     string
+	expiresAt time.Time
+	now       func() time.Time
+}
+
+func (ap *AppAuthenticationClient) Do(request *http.Request) (*http.Response, error) {
+	token, err := ap.installationToken(request)
+	if nil != err {
+		return nil, err
+	}
+
+	request.Header.Set("Authorization", fmt.Sprintf("token %s", token))
+
+	response, err := ap.inner.Do(request)
+	if nil == err && response.StatusCode == http.StatusUnauthorized {
+		ap.invalidate()
+	}
+
+	return response, err
+}
+
+func (ap *AppAuthenticationClient) installationToken(request *http.Request) (string, error) {
+	ap.mutex.Lock()
+	defer ap.mutex.Unlock()
+
+	if ap.token != "" && ap.now().Add(tokenRefreshBefore).Before(ap.expiresAt) {
+		return ap.token, nil
+	}
+
+	token, expiresAt, err := ap.exchange(request)
+	if nil != err {
+		return "", err
+	}
+
+	ap.token = token
+	ap.expiresAt = expiresAt
+
+	return token, nil
+}
+
+func (ap *AppAuthenticationClient) invalidate() {
+	ap.mutex.Lock()
+	ap.token = ""
+	ap.mutex.Unlock()
+}
+
+func (ap *AppAuthenticationClient) exchange(original *http.Request) (string, time.Time, error) {
+	jwt, err := ap.signJWT()
+	if nil != err {
+		return "", time.Time{}, err
+	}
+
+	request, _ := http.NewRequest("POST", ap.tokenURL.String(), nil)
+	request = request.WithContext(original.Context())
+	request.Header.Set("Accept", "application/vnd.github.v3+json")
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", jwt))
+
+	response, err := ap.inner.Do(request)
+	if nil != err {
+		return "", time.Time{}, err
+	}
+	defer response.Body.Close()
+
+	result := &installationTokenResponse{}
+	decodeErr := json.NewDecoder(response.Body).Decode(result)
+
+	if response.StatusCode != http.StatusCreated {
+		return "", time.Time{}, fmt.Errorf("%s %s: %w", response.Status, result.Message, ErrAppAuthentication)
+	}
+
+	if nil != decodeErr || result.Token == "" {
+		return "", time.Time{}, fmt.Errorf("invalid installation token response: %w", ErrAppAuthentication)
+	}
+
+	return result.Token, result.ExpiresAt, nil
+}
+
+func (ap *AppAuthenticationClient) signJWT() (string, error) {
+	now := ap.now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-jwtClockDrift).Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+		"iss": ap.appID,
+	})
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, ap.key, crypto.SHA256, digest[:])
+	if nil != err {
+		return "", fmt.Errorf("%s: %w", err.Error(), ErrAppAuthentication)
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+type installationTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Message   string    `json:"message"`
+}
@@ -0,0 +1,244 @@
+package http
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/smartystreets/gunit"
+)
+
+var (
+	appKey     *rsa.PrivateKey
+	appKeyOnce sync.Once
+)
+
+func TestAppAuthenticationClient(t *testing.T) {
+	appKeyOnce.Do(func() {
+		appKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+	})
+
+	gunit.Run(new(AppAuthenticationClientFixture), t)
+}
+
+type AppAuthenticationClientFixture struct {
+	*gunit.Fixture
+
+	server *FakeTokenServer
+	inner  *FakeTokenExchangeClient
+	client *AppAuthenticationClient
+	now    time.Time
+}
+
+func (aaf *AppAuthenticationClientFixture) Setup() {
+	aaf.now = time.Date(2020, 9, 13, 12, 0, 0, 0, time.UTC)
+	aaf.server = NewFakeTokenServer(&appKey.PublicKey)
+	aaf.server.expiresAt = aaf.now.Add(time.Hour)
+	apiURL, _ := url.Parse(aaf.server.URL + "/api/v3")
+	aaf.inner = &FakeTokenExchangeClient{FakeSequenceHTTPClient: &FakeSequenceHTTPClient{}}
+	aaf.client = NewAppAuthenticationClient(aaf.inner, "12345", "678", appKey, apiURL)
+	aaf.client.now = func() time.Time { return aaf.now }
+}
+
+func (aaf *AppAuthenticationClientFixture) Teardown() {
+	aaf.server.Close()
+}
+
+func (aaf *AppAuthenticationClientFixture) TestInstallationTokenUsed() {
+	aaf.inner.Add(http.StatusOK, `{}`, nil)
+
+	response, err := aaf.client.Do(aaf.newRequest())
+
+	aaf.So(err, should.BeNil)
+	aaf.So(response.StatusCode, should.Equal, http.StatusOK)
+	aaf.So(aaf.inner.headers[0].Get("Authorization"), should.Equal, "token installation-token-1")
+	aaf.So(aaf.server.paths, should.Resemble, []string{"/api/v3/app/installations/678/access_tokens"})
+}
+
+func (aaf *AppAuthenticationClientFixture) TestJWTSignedWithAppKey() {
+	aaf.inner.Add(http.StatusOK, `{}`, nil)
+
+	aaf.client.Do(aaf.newRequest())
+
+	aaf.So(aaf.server.errors, should.BeEmpty)
+	aaf.So(aaf.server.claims["iss"], should.Equal, "12345")
+	aaf.So(aaf.server.claims["iat"], should.Equal, float64(aaf.now.Add(-time.Minute).Unix()))
+	aaf.So(aaf.server.claims["exp"], should.Equal, float64(aaf.now.Add(9*time.Minute).Unix()))
+}
+
+func (aaf *AppAuthenticationClientFixture) TestTokenCached() {
+	aaf.inner.Add(http.StatusOK, `{}`, nil)
+	aaf.inner.Add(http.StatusOK, `{}`, nil)
+
+	aaf.client.Do(aaf.newRequest())
+	aaf.now = aaf.now.Add(50 * time.Minute)
+	aaf.client.Do(aaf.newRequest())
+
+	aaf.So(aaf.server.paths, should.HaveLength, 1)
+	aaf.So(aaf.inner.headers[1].Get("Authorization"), should.Equal, "token installation-token-1")
+}
+
+func (aaf *AppAuthenticationClientFixture) TestTokenRefreshedBeforeExpiry() {
+	aaf.inner.Add(http.StatusOK, `{}`, nil)
+	aaf.inner.Add(http.StatusOK, `{}`, nil)
+
+	aaf.client.Do(aaf.newRequest())
+	aaf.now = aaf.now.Add(56 * time.Minute)
+	aaf.client.Do(aaf.newRequest())
+
+	aaf.So(aaf.server.paths, should.HaveLength, 2)
+	aaf.So(aaf.inner.headers[1].Get("Authorization"), should.Equal, "token installation-token-2")
+}
+
+func (aaf *AppAuthenticationClientFixture) TestUnauthorizedResponseInvalidatesToken() {
+	aaf.inner.Add(http.StatusUnauthorized, `{"message":"Bad credentials"}`, nil)
+	aaf.inner.Add(http.StatusOK, `{}`, nil)
+
+	aaf.client.Do(aaf.newRequest())
+	aaf.client.Do(aaf.newRequest())
+
+	aaf.So(aaf.server.paths, should.HaveLength, 2)
+}
+
+func (aaf *AppAuthenticationClientFixture) TestExchangeFailure() {
+	aaf.server.statusCode = http.StatusNotFound
+
+	_, err := aaf.client.Do(aaf.newRequest())
+
+	aaf.So(errors.Is(err, ErrAppAuthentication), should.BeTrue)
+	aaf.So(err.Error(), should.Equal, "404 Not Found Not Found: github app authentication error")
+	aaf.So(aaf.inner.headers, should.BeEmpty)
+}
+
+func (aaf *AppAuthenticationClientFixture) TestLoadPrivateKeyPKCS1() {
+	file := aaf.writeKey(x509.MarshalPKCS1PrivateKey(appKey))
+	defer os.Remove(file)
+
+	key, err := LoadPrivateKey(file)
+
+	aaf.So(err, should.BeNil)
+	aaf.So(key.N.Cmp(appKey.N), should.Equal, 0)
+}
+
+func (aaf *AppAuthenticationClientFixture) TestLoadPrivateKeyPKCS8() {
+	content, _ := x509.MarshalPKCS8PrivateKey(appKey)
+	file := aaf.writeKey(content)
+	defer os.Remove(file)
+
+	key, err := LoadPrivateKey(file)
+
+	aaf.So(err, should.BeNil)
+	aaf.So(key.N.Cmp(appKey.N), should.Equal, 0)
+}
+
+func (aaf *AppAuthenticationClientFixture) TestLoadPrivateKeyInvalid() {
+	file, _ := ioutil.TempFile("", "app-key")
+	file.WriteString("not a key")
+	file.Close()
+	defer os.Remove(file.Name())
+
+	_, err := LoadPrivateKey(file.Name())
+
+	aaf.So(errors.Is(err, ErrAppAuthentication), should.BeTrue)
+}
+
+func (aaf *AppAuthenticationClientFixture) writeKey(content []byte) string {
+	file, _ := ioutil.TempFile("", "app-key")
+	pem.Encode(file, &pem.Block{Type: "PRIVATE KEY", Bytes: content})
+	file.Close()
+
+	return file.Name()
+}
+
+func (aaf *AppAuthenticationClientFixture) newRequest() *http.Request {
+	request, _ := http.NewRequest("POST", "", strings.NewReader("query"))
+
+	return request
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// FakeTokenExchangeClient sends token exchange requests to the fake server and
+// answers every other request from the sequence.
+type FakeTokenExchangeClient struct {
+	*FakeSequenceHTTPClient
+}
+
+func (fc *FakeTokenExchangeClient) Do(request *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(request.URL.Path, "/access_tokens") {
+		return http.DefaultClient.Do(request)
+	}
+
+	return fc.FakeSequenceHTTPClient.Do(request)
+}
+
+type FakeTokenServer struct {
+	*httptest.Server
+
+	key        *rsa.PublicKey
+	statusCode int
+	expiresAt  time.Time
+	paths      []string
+	claims     map[string]interface{}
+	errors     []string
+}
+
+func NewFakeTokenServer(key *rsa.PublicKey) *FakeTokenServer {
+	fs := &FakeTokenServer{key: key, statusCode: http.StatusCreated}
+	fs.Server = httptest.NewServer(http.HandlerFunc(fs.handle))
+
+	return fs
+}
+
+func (fs *FakeTokenServer) handle(writer http.ResponseWriter, request *http.Request) {
+	fs.paths = append(fs.paths, request.URL.Path)
+	fs.verify(strings.TrimPrefix(request.Header.Get("Authorization"), "Bearer "))
+
+	writer.WriteHeader(fs.statusCode)
+
+	if fs.statusCode != http.StatusCreated {
+		writer.Write([]byte(`{"message":"Not Found"}`))
+		return
+	}
+
+	json.NewEncoder(writer).Encode(map[string]interface{}{
+		"token":      fmt.Sprintf("installation-token-%d", len(fs.paths)),
+		"expires_at": fs.expiresAt.Add(time.Duration(len(fs.paths)-1) * time.Hour),
+	})
+}
+
+func (fs *FakeTokenServer) verify(jwt string) {
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		fs.errors = append(fs.errors, "malformed jwt")
+		return
+	}
+
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(fs.key, crypto.SHA256, digest[:], signature); nil != err {
+		fs.errors = append(fs.errors, err.Error())
+	}
+
+	claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	fs.claims = map[string]interface{}{}
+	json.Unmarshal(claims, &fs.claims)
+}
@@ -0,0 +1,216 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var ErrCacheMiss = errors.New("response not cached")
+
+const (
+	cacheFileExtension = ".json"
+	temporaryExtension = ".tmp"
+)
+
+// ClearCache removes every cached response from the directory, with the temporary
+// files an interrupted run left behind.
+func ClearCache(directory string) error {
+	for _, pattern := range []string{"*" + cacheFileExtension, "[0-9a-f]*" + temporaryExtension + "*"} {
+		files, err := filepath.Glob(filepath.Join(directory, pattern))
+		if nil != err {
+			return err
+		}
+
+		for _, file := range files {
+			if err := os.Remove(file); nil != err {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func NewCacheClient(inner Client, directory string, ttl time.Duration, offline bool) *CacheClient {
+	return &CacheClient{
+		inner:     inner,
+		directory: directory,
+		ttl:       ttl,
+		offline:   offline,
+		now:       time.Now,
+	}
+}
+
+// CacheClient stores successful responses on disk, keyed by the request URL and body.
+// GraphQL failures, which github returns with status 200, are not stored.
+// Fresh responses are served from the cache, stale ones are revalidated with their
+// ETag. In offline mode only cached responses are served.
+type CacheClient struct {
+	inner     Client
+	directory string
+	ttl       time.Duration
+	offline   bool
+	now       func() time.Time
+}
+
+type cacheEntry struct {
+	StoredAt   time.Time   `json:"storedAt"`
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+}
+
+func (cc *CacheClient) Do(request *http.Request) (*http.Response, error) {
+	body, err := bufferBody(request)
+	if nil != err {
+		return nil, err
+	}
+	replayBody(request, body)
+
+	key := cc.key(request, body)
+	entry := cc.load(key)
+
+	if nil != entry && (cc.offline || cc.now().Sub(entry.StoredAt) < cc.ttl) {
+		return entry.response(request), nil
+	}
+
+	if cc.offline {
+		return nil, fmt.Errorf("offline, %s %s: %w", request.Method, request.URL, ErrCacheMiss)
+	}
+
+	if nil != entry && entry.Header.Get("ETag") != "" {
+		request.Header.Set("If-None-Match", entry.Header.Get("ETag"))
+	}
+
+	response, err := cc.inner.Do(request)
+	if nil != err {
+		return response, err
+	}
+
+	if response.StatusCode == http.StatusNotModified && nil != entry {
+		response.Body.Close()
+		entry.StoredAt = cc.now()
+		cc.store(key, entry)
+
+		return entry.response(request), nil
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return response, nil
+	}
+
+	return cc.save(key, response)
+}
+
+func (cc *CacheClient) key(request *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(request.Method + " " + request.URL.String() + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (cc *CacheClient) load(key string) *cacheEntry {
+	content, err := ioutil.ReadFile(cc.file(key))
+	if nil != err {
+		return nil
+	}
+
+	entry := &cacheEntry{}
+	if err := json.Unmarshal(content, entry); nil != err {
+		return nil
+	}
+
+	return entry
+}
+
+func (cc *CacheClient) save(key string, response *http.Response) (*http.Response, error) {
+	content, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+
+	if nil != err {
+		return nil, err
+	}
+
+	response.Body = ioutil.NopCloser(bytes.NewReader(content))
+	if !isCacheable(content) {
+		return response, nil
+	}
+
+	cc.store(key, &cacheEntry{
+		StoredAt:   cc.now(),
+		StatusCode: response.StatusCode,
+		Header:     response.Header,
+		Body:       content,
+	})
+
+	return response, nil
+}
+
+// store writes the entry to a temporary file first, so an interrupted run never
+// leaves a truncated entry behind. Failing to cache a response is not an error.
+func (cc *CacheClient) store(key string, entry *cacheEntry) {
+	content, err := json.Marshal(entry)
+	if nil != err {
+		return
+	}
+
+	if err := os.MkdirAll(cc.directory, 0700); nil != err {
+		return
+	}
+
+	temporary, err := ioutil.TempFile(cc.directory, key+temporaryExtension)
+	if nil != err {
+		return
+	}
+
+	_, err = temporary.Write(content)
+	temporary.Close()
+
+	if nil != err {
+		os.Remove(temporary.Name())
+		return
+	}
+
+	if err := os.Rename(temporary.Name(), cc.file(key)); nil != err {
+		os.Remove(temporary.Name())
+	}
+}
+
+// isCacheable tells whether the body is a GraphQL response without errors, e.g. not
+// a rate limit or a timeout reported in the errors or the message.
+func isCacheable(body []byte) bool {
+	result := &struct {
+		Message string            `json:"message"`
+		Errors  []json.RawMessage `json:"errors"`
+	}{}
+
+	if err := json.Unmarshal(body, result); nil != err {
+		return false
+	}
+
+	return result.Message == "" && len(result.Errors) == 0
+}
+
+func (cc *CacheClient) file(key string) string {
+	return filepath.Join(cc.directory, key+cacheFileExtension)
+}
+
+func (ce *cacheEntry) response(request *http.Request) *http.Response {
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", ce.StatusCode, http.StatusText(ce.StatusCode)),
+		StatusCode: ce.StatusCode,
+		Header:     ce.Header,
+		Body:       ioutil.NopCloser(bytes.NewReader(ce.Body)),
+		Request:    request,
+	}
+}
@@ -0,0 +1,203 @@
+package http
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/smartystreets/gunit"
+)
+
+func TestCacheClient(t *testing.T) {
+	gunit.Run(new(CacheClientFixture), t)
+}
+
+type CacheClientFixture struct {
+	*gunit.Fixture
+
+	directory string
+	inner     *FakeSequenceHTTPClient
+	client    *CacheClient
+	now       time.Time
+}
+
+func (ccf *CacheClientFixture) Setup() {
+	ccf.directory, _ = ioutil.TempDir("", "cache-client")
+	ccf.now = time.Date(2020, 9, 13, 12, 0, 0, 0, time.UTC)
+	ccf.inner = &FakeSequenceHTTPClient{}
+	ccf.client = ccf.newClient(false)
+}
+
+func (ccf *CacheClientFixture) Teardown() {
+	os.RemoveAll(ccf.directory)
+}
+
+func (ccf *CacheClientFixture) TestResponseCached() {
+	ccf.inner.Add(http.StatusOK, `{"data":1}`, nil)
+
+	first, _ := ccf.client.Do(ccf.newRequest("query"))
+	second, err := ccf.client.Do(ccf.newRequest("query"))
+
+	ccf.So(err, should.BeNil)
+	ccf.So(ccf.readBody(first), should.Equal, `{"data":1}`)
+	ccf.So(ccf.readBody(second), should.Equal, `{"data":1}`)
+	ccf.So(second.StatusCode, should.Equal, http.StatusOK)
+	ccf.So(ccf.inner.callNr, should.Equal, 1)
+	ccf.So(ccf.inner.bodies, should.Resemble, []string{"query"})
+}
+
+func (ccf *CacheClientFixture) TestDifferentBodiesCachedSeparately() {
+	ccf.inner.Add(http.StatusOK, `{"data":1}`, nil)
+	ccf.inner.Add(http.StatusOK, `{"data":2}`, nil)
+
+	ccf.client.Do(ccf.newRequest("query1"))
+	response, _ := ccf.client.Do(ccf.newRequest("query2"))
+
+	ccf.So(ccf.readBody(response), should.Equal, `{"data":2}`)
+	ccf.So(ccf.inner.callNr, should.Equal, 2)
+}
+
+func (ccf *CacheClientFixture) TestErrorResponsesNotCached() {
+	ccf.inner.Add(http.StatusBadGateway, `bad gateway`, nil)
+	ccf.inner.Add(http.StatusOK, `{"data":1}`, nil)
+
+	first, _ := ccf.client.Do(ccf.newRequest("query"))
+	second, _ := ccf.client.Do(ccf.newRequest("query"))
+
+	ccf.So(first.StatusCode, should.Equal, http.StatusBadGateway)
+	ccf.So(ccf.readBody(second), should.Equal, `{"data":1}`)
+	ccf.So(ccf.inner.callNr, should.Equal, 2)
+}
+
+func (ccf *CacheClientFixture) TestGraphQLErrorsNotCached() {
+	ccf.inner.Add(http.StatusOK, `{"errors":[{"type":"RATE_LIMITED","message":"API rate limit exceeded"}]}`, nil)
+	ccf.inner.Add(http.StatusOK, `{"message":"Something went wrong while executing your query."}`, nil)
+	ccf.inner.Add(http.StatusOK, `{"data":1}`, nil)
+
+	first, _ := ccf.client.Do(ccf.newRequest("query"))
+	second, _ := ccf.client.Do(ccf.newRequest("query"))
+	third, _ := ccf.client.Do(ccf.newRequest("query"))
+	cached, _ := ccf.newClient(true).Do(ccf.newRequest("query"))
+
+	ccf.So(ccf.readBody(first), should.ContainSubstring, "RATE_LIMITED")
+	ccf.So(ccf.readBody(second), should.ContainSubstring, "Something went wrong")
+	ccf.So(ccf.readBody(third), should.Equal, `{"data":1}`)
+	ccf.So(ccf.readBody(cached), should.Equal, `{"data":1}`)
+	ccf.So(ccf.inner.callNr, should.Equal, 3)
+}
+
+func (ccf *CacheClientFixture) TestExpiredResponseFetchedAgain() {
+	ccf.inner.Add(http.StatusOK, `{"data":1}`, nil)
+	ccf.inner.Add(http.StatusOK, `{"data":2}`, nil)
+
+	ccf.client.Do(ccf.newRequest("query"))
+	ccf.now = ccf.now.Add(2 * time.Hour)
+	response, _ := ccf.client.Do(ccf.newRequest("query"))
+
+	ccf.So(ccf.readBody(response), should.Equal, `{"data":2}`)
+	ccf.So(ccf.inner.headers[1].Get("If-None-Match"), should.Equal, "")
+}
+
+func (ccf *CacheClientFixture) TestExpiredResponseRevalidated() {
+	ccf.inner.Add(http.StatusOK, `{"data":1}`, http.Header{"Etag": {`"abc"`}})
+	ccf.inner.Add(http.StatusNotModified, ``, nil)
+	ccf.inner.Add(http.StatusOK, `{"data":2}`, nil)
+
+	ccf.client.Do(ccf.newRequest("query"))
+	ccf.now = ccf.now.Add(2 * time.Hour)
+	revalidated, _ := ccf.client.Do(ccf.newRequest("query"))
+	ccf.now = ccf.now.Add(30 * time.Minute)
+	fresh, _ := ccf.client.Do(ccf.newRequest("query"))
+
+	ccf.So(ccf.inner.headers[1].Get("If-None-Match"), should.Equal, `"abc"`)
+	ccf.So(revalidated.StatusCode, should.Equal, http.StatusOK)
+	ccf.So(ccf.readBody(revalidated), should.Equal, `{"data":1}`)
+	ccf.So(ccf.readBody(fresh), should.Equal, `{"data":1}`)
+	ccf.So(ccf.inner.callNr, should.Equal, 2)
+}
+
+func (ccf *CacheClientFixture) TestOfflineServesStaleResponses() {
+	ccf.inner.Add(http.StatusOK, `{"data":1}`, nil)
+	ccf.client.Do(ccf.newRequest("query"))
+
+	ccf.client = ccf.newClient(true)
+	ccf.now = ccf.now.Add(48 * time.Hour)
+	response, err := ccf.client.Do(ccf.newRequest("query"))
+
+	ccf.So(err, should.BeNil)
+	ccf.So(ccf.readBody(response), should.Equal, `{"data":1}`)
+	ccf.So(ccf.inner.callNr, should.Equal, 1)
+}
+
+func (ccf *CacheClientFixture) TestOfflineMiss() {
+	ccf.client = ccf.newClient(true)
+
+	_, err := ccf.client.Do(ccf.newRequest("query"))
+
+	ccf.So(errors.Is(err, ErrCacheMiss), should.BeTrue)
+	ccf.So(ccf.inner.callNr, should.Equal, 0)
+}
+
+func (ccf *CacheClientFixture) TestClearCache() {
+	ccf.inner.Add(http.StatusOK, `{"data":1}`, nil)
+	ccf.inner.Add(http.StatusOK, `{"data":2}`, nil)
+	ioutil.WriteFile(filepath.Join(ccf.directory, "notes.txt"), []byte("keep"), 0600)
+	ioutil.WriteFile(filepath.Join(ccf.directory, "0a1b.tmp123"), []byte("{"), 0600)
+
+	ccf.client.Do(ccf.newRequest("query"))
+	err := ClearCache(ccf.directory)
+	remaining := ccf.files()
+	response, _ := ccf.client.Do(ccf.newRequest("query"))
+	_, notesErr := os.Stat(filepath.Join(ccf.directory, "notes.txt"))
+
+	ccf.So(err, should.BeNil)
+	ccf.So(ccf.readBody(response), should.Equal, `{"data":2}`)
+	ccf.So(notesErr, should.BeNil)
+	ccf.So(remaining, should.Resemble, []string{"notes.txt"})
+}
+
+func (ccf *CacheClientFixture) TestInnerErrorReturned() {
+	ccf.inner.err = errors.New("HTTP Error")
+
+	_, err := ccf.client.Do(ccf.newRequest("query"))
+
+	ccf.So(err.Error(), should.Equal, "HTTP Error")
+}
+
+func (ccf *CacheClientFixture) files() []string {
+	var names []string
+
+	entries, _ := ioutil.ReadDir(ccf.directory)
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	return names
+}
+
+func (ccf *CacheClientFixture) newClient(offline bool) *CacheClient {
+	client := NewCacheClient(ccf.inner, ccf.directory, time.Hour, offline)
+	client.now = func() time.Time { return ccf.now }
+
+	return client
+}
+
+func (ccf *CacheClientFixture) newRequest(body string) *http.Request {
+	request, _ := http.NewRequest("POST", "https://api.github.com/graphql", strings.NewReader(body))
+
+	return request
+}
+
+func (ccf *CacheClientFixture) readBody(response *http.Response) string {
+	defer response.Body.Close()
+	content, _ := ioutil.ReadAll(response.Body)
+
+	return string(content)
+}
@@ -0,0 +1,181 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+var ErrCassette = errors.New("cassette error")
+
+const redacted = "REDACTED"
+
+// Cassette holds recorded request/response pairs.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header"`
+	Body   string      `json:"body"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+}
+
+// LoadCassette reads a cassette file written by a recording CassetteClient.
+func LoadCassette(file string) (*Cassette, error) {
+	content, err := ioutil.ReadFile(file)
+	if nil != err {
+		return nil, err
+	}
+
+	cassette := &Cassette{}
+	if err := json.Unmarshal(content, cassette); nil != err {
+		return nil, fmt.Errorf("%s: %s: %w", file, err.Error(), ErrCassette)
+	}
+
+	return cassette, nil
+}
+
+// NewRecordingClient sends requests to the inner client and records every exchange.
+// The cassette is written by Close.
+func NewRecordingClient(inner Client, file string) *CassetteClient {
+	return &CassetteClient{inner: inner, file: file, cassette: &Cassette{}}
+}
+
+// NewReplayingClient answers requests from the cassette, without network access.
+func NewReplayingClient(cassette *Cassette) *CassetteClient {
+	return &CassetteClient{cassette: cassette, played: map[*Interaction]bool{}}
+}
+
+// CassetteClient records request/response pairs to a cassette file, or replays them
+// by matching request bodies. Authorization headers are never recorded.
+type CassetteClient struct {
+	inner    Client
+	file     string
+	mutex    sync.Mutex
+	cassette *Cassette
+	played   map[*Interaction]bool
+}
+
+func (cc *CassetteClient) Do(request *http.Request) (*http.Response, error) {
+	body, err := bufferBody(request)
+	if nil != err {
+		return nil, err
+	}
+	replayBody(request, body)
+
+	if nil == cc.inner {
+		return cc.replay(request, string(body))
+	}
+
+	return cc.record(request, string(body))
+}
+
+// Close writes the recorded cassette to its file.
+func (cc *CassetteClient) Close() error {
+	if nil == cc.inner {
+		return nil
+	}
+
+	cc.mutex.Lock()
+	defer cc.mutex.Unlock()
+
+	content, err := json.MarshalIndent(cc.cassette, "", "  ")
+	if nil != err {
+		return err
+	}
+
+	return ioutil.WriteFile(cc.file, content, 0600)
+}
+
+// replay returns the first unplayed interaction with the same body; once all matches
+// were played the last one keeps being returned.
+func (cc *CassetteClient) replay(request *http.Request, body string) (*http.Response, error) {
+	cc.mutex.Lock()
+	defer cc.mutex.Unlock()
+
+	var match *Interaction
+
+	for _, interaction := range cc.cassette.Interactions {
+		if interaction.Request.Body != body {
+			continue
+		}
+
+		match = interaction
+		if !cc.played[interaction] {
+			break
+		}
+	}
+
+	if nil == match {
+		return nil, fmt.Errorf("no interaction for %s %s: %w", request.Method, request.URL, ErrCassette)
+	}
+
+	cc.played[match] = true
+
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", match.Response.StatusCode, http.StatusText(match.Response.StatusCode)),
+		StatusCode: match.Response.StatusCode,
+		Header:     match.Response.Header,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(match.Response.Body)),
+		Request:    request,
+	}, nil
+}
+
+func (cc *CassetteClient) record(request *http.Request, body string) (*http.Response, error) {
+	response, err := cc.inner.Do(request)
+	if nil != err {
+		return response, err
+	}
+
+	content, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	response.Body = ioutil.NopCloser(bytes.NewReader(content))
+
+	if nil != err {
+		return nil, err
+	}
+
+	cc.mutex.Lock()
+	cc.cassette.Interactions = append(cc.cassette.Interactions, &Interaction{
+		Request: RecordedRequest{
+			Method: request.Method,
+			URL:    request.URL.String(),
+			Header: redact(request.Header),
+			Body:   body,
+		},
+		Response: RecordedResponse{
+			StatusCode: response.StatusCode,
+			Header:     response.Header,
+			Body:       string(content),
+		},
+	})
+	cc.mutex.Unlock()
+
+	return response, nil
+}
+
+func redact(header http.Header) http.Header {
+	copied := header.Clone()
+	if copied.Get("Authorization") != "" {
+		copied.Set("Authorization", redacted)
+	}
+
+	return copied
+}
@@ -0,0 +1,143 @@
+package http
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/smartystreets/gunit"
+)
+
+func TestCassetteClient(t *testing.T) {
+	gunit.Run(new(CassetteClientFixture), t)
+}
+
+type CassetteClientFixture struct {
+	*gunit.Fixture
+
+	directory string
+	file      string
+	inner     *FakeSequenceHTTPClient
+}
+
+func (ccf *CassetteClientFixture) Setup() {
+	ccf.directory, _ = ioutil.TempDir("", "cassette-client")
+	ccf.file = filepath.Join(ccf.directory, "cassette.json")
+	ccf.inner = &FakeSequenceHTTPClient{}
+}
+
+func (ccf *CassetteClientFixture) Teardown() {
+	os.RemoveAll(ccf.directory)
+}
+
+func (ccf *CassetteClientFixture) TestRecordedResponsePassedThrough() {
+	ccf.inner.Add(http.StatusOK, `{"data":1}`, nil)
+	client := NewRecordingClient(ccf.inner, ccf.file)
+
+	response, err := client.Do(ccf.newRequest("query1"))
+
+	ccf.So(err, should.BeNil)
+	ccf.So(ccf.readBody(response), should.Equal, `{"data":1}`)
+	ccf.So(ccf.inner.bodies, should.Resemble, []string{"query1"})
+}
+
+func (ccf *CassetteClientFixture) TestRecordAndReplay() {
+	ccf.record()
+
+	cassette, err := LoadCassette(ccf.file)
+	client := NewReplayingClient(cassette)
+	second, _ := client.Do(ccf.newRequest("query2"))
+	first, _ := client.Do(ccf.newRequest("query1"))
+
+	ccf.So(err, should.BeNil)
+	ccf.So(ccf.readBody(first), should.Equal, `{"data":1}`)
+	ccf.So(ccf.readBody(second), should.Equal, `{"data":2}`)
+	ccf.So(second.StatusCode, should.Equal, http.StatusBadGateway)
+	ccf.So(second.Header.Get("X-Test"), should.Equal, "value")
+}
+
+func (ccf *CassetteClientFixture) TestAuthorizationRedacted() {
+	ccf.record()
+
+	content, _ := ioutil.ReadFile(ccf.file)
+	cassette, _ := LoadCassette(ccf.file)
+
+	ccf.So(string(content), should.NotContainSubstring, "secret")
+	ccf.So(cassette.Interactions[0].Request.Header.Get("Authorization"), should.Equal, "REDACTED")
+	ccf.So(cassette.Interactions[0].Request.Header.Get("Content-Type"), should.Equal, "application/json")
+}
+
+func (ccf *CassetteClientFixture) TestRepeatedRequestsReplayedInOrder() {
+	ccf.inner.Add(http.StatusOK, `{"data":1}`, nil)
+	ccf.inner.Add(http.StatusOK, `{"data":2}`, nil)
+	recorder := NewRecordingClient(ccf.inner, ccf.file)
+	recorder.Do(ccf.newRequest("query"))
+	recorder.Do(ccf.newRequest("query"))
+	recorder.Close()
+
+	cassette, _ := LoadCassette(ccf.file)
+	client := NewReplayingClient(cassette)
+	first, _ := client.Do(ccf.newRequest("query"))
+	second, _ := client.Do(ccf.newRequest("query"))
+	third, _ := client.Do(ccf.newRequest("query"))
+
+	ccf.So(ccf.readBody(first), should.Equal, `{"data":1}`)
+	ccf.So(ccf.readBody(second), should.Equal, `{"data":2}`)
+	ccf.So(ccf.readBody(third), should.Equal, `{"data":2}`)
+}
+
+func (ccf *CassetteClientFixture) TestUnknownRequestNotReplayed() {
+	client := NewReplayingClient(&Cassette{})
+
+	_, err := client.Do(ccf.newRequest("query"))
+
+	ccf.So(errors.Is(err, ErrCassette), should.BeTrue)
+}
+
+func (ccf *CassetteClientFixture) TestInvalidCassette() {
+	ioutil.WriteFile(ccf.file, []byte("not json"), 0600)
+
+	_, err := LoadCassette(ccf.file)
+
+	ccf.So(errors.Is(err, ErrCassette), should.BeTrue)
+}
+
+func (ccf *CassetteClientFixture) TestInnerErrorReturned() {
+	ccf.inner.err = errors.New("HTTP Error")
+	client := NewRecordingClient(ccf.inner, ccf.file)
+
+	_, err := client.Do(ccf.newRequest("query"))
+
+	ccf.So(err.Error(), should.Equal, "HTTP Error")
+}
+
+func (ccf *CassetteClientFixture) record() {
+	ccf.inner.Add(http.StatusOK, `{"data":1}`, nil)
+	ccf.inner.Add(http.StatusBadGateway, `{"data":2}`, http.Header{"X-Test": {"value"}})
+	recorder := NewRecordingClient(ccf.inner, ccf.file)
+
+	recorder.Do(ccf.newRequest("query1"))
+	recorder.Do(ccf.newRequest("query2"))
+	recorder.Close()
+}
+
+func (ccf *CassetteClientFixture) newRequest(body string) *http.Request {
+	request, _ := http.NewRequest("POST", "https://api.github.com/graphql", strings.NewReader(body))
+	request.Header.Set("Authorization", "bearer secret")
+	request.Header.Set("Content-Type", "application/json")
+
+	return request
+}
+
+func (ccf *CassetteClientFixture) readBody(response *http.Response) string {
+	defer response.Body.Close()
+	content, _ := ioutil.ReadAll(response.Body)
+
+	return string(content)
+}
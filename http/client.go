@@ -3,29 +3,50 @@ package http
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 )
 
+// Client sends requests like *http.Client does. The request context bounds the whole
+// call: implementations that wait (rate limits, retries) stop waiting once it is done.
 type Client interface {
 	Do(r *http.Request) (*http.Response, error)
 }
 
-func NewAuthenticationClientV4(inner Client, authToken string) *AuthenticationClientV4 {
-	return &AuthenticationClientV4{
+// AuthenticationOption configures an AuthenticationClientV4.
+type AuthenticationOption func(ac *AuthenticationClientV4)
+
+// WithEndpoint sends the requests to the given GraphQL endpoint instead of github.com.
+func WithEndpoint(endpoint *url.URL) AuthenticationOption {
+	return func(ac *AuthenticationClientV4) {
+		ac.endpoint = endpoint
+	}
+}
+
+func NewAuthenticationClientV4(inner Client, authToken string, options ...AuthenticationOption) *AuthenticationClientV4 {
+	client := &AuthenticationClientV4{
 		inner:     inner,
 		authToken: authToken,
+		endpoint:  DefaultEndpoint(),
 	}
+
+	for _, option := range options {
+		option(client)
+	}
+
+	return client
 }
 
 type AuthenticationClientV4 struct {
 	inner     Client
 	authToken string
+	endpoint  *url.URL
 }
 
 func (ac *AuthenticationClientV4) Do(request *http.Request) (*http.Response, error) {
-	request.URL.Scheme = "https"
-	request.URL.Host = "api.github.com"
-	request.Host = "api.github.com"
-	request.URL.Path = "/graphql"
+	request.URL.Scheme = ac.endpoint.Scheme
+	request.URL.Host = ac.endpoint.Host
+	request.Host = ac.endpoint.Host
+	request.URL.Path = ac.endpoint.Path
 	request.Header.Add("Accept", "application/json")
 	request.Header.Add("Content-Type", "application/json")
 
@@ -61,6 +61,21 @@ func (acf *AuthenticationClientFixture) TestMissingToken() {
 	acf.So(acf.inner.request.Header.Get("Authorization"), should.Equal, "")
 }
 
+func (acf *AuthenticationClientFixture) TestConfiguredEndpoint() {
+	endpoint, _ := ParseEndpoint("http://localhost:8080")
+	acf.client = NewAuthenticationClientV4(acf.inner, "authtoken", WithEndpoint(endpoint))
+	request := httptest.NewRequest("GET", "/path?existingKey=existingValue", nil)
+
+	acf.client.Do(request)
+
+	acf.So(acf.inner.request.URL.Scheme, should.Equal, "http")
+	acf.So(acf.inner.request.URL.Host, should.Equal, "localhost:8080")
+	acf.So(acf.inner.request.Host, should.Equal, "localhost:8080")
+	acf.So(acf.inner.request.URL.Path, should.Equal, "/api/graphql")
+	acf.assertQueryStringIncludesAuthentication()
+	acf.assertQueryStringValue("existingKey", "existingValue")
+}
+
 func (acf *AuthenticationClientFixture) assertQueryStringIncludesAuthentication() {
 	acf.So(acf.inner.request.Header.Get("Authorization"), should.Equal, "bearer authtoken")
 }
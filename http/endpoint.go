@@ -0,0 +1,70 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+var ErrEndpoint = errors.New("invalid endpoint")
+
+const (
+	githubHost    = "github.com"
+	githubAPIHost = "api.github.com"
+)
+
+// DefaultEndpoint returns the GraphQL endpoint of github.com.
+func DefaultEndpoint() *url.URL {
+	return &url.URL{Scheme: "https", Host: githubAPIHost, Path: "/graphql"}
+}
+
+// ParseEndpoint resolves a host name or URL to a GraphQL endpoint. Bare hosts default
+// to https; GitHub Enterprise Server hosts get the /api/graphql path unless an explicit
+// path is given.
+func ParseEndpoint(host string) (*url.URL, error) {
+	host = strings.TrimSpace(host)
+	if host == "" {
+		return DefaultEndpoint(), nil
+	}
+
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+
+	endpoint, err := url.Parse(host)
+	if nil != err {
+		return nil, fmt.Errorf("%s: %w", err.Error(), ErrEndpoint)
+	}
+
+	if endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("%s: %w", host, ErrEndpoint)
+	}
+
+	if endpoint.Hostname() == githubHost || endpoint.Hostname() == githubAPIHost {
+		return DefaultEndpoint(), nil
+	}
+
+	if endpoint.Path == "" || endpoint.Path == "/" {
+		endpoint.Path = "/api/graphql"
+	}
+
+	return endpoint, nil
+}
+
+// RESTEndpoint returns the REST API root matching a GraphQL endpoint: api.github.com
+// for github.com and the /api/v3 path for GitHub Enterprise Server.
+func RESTEndpoint(graphql *url.URL) *url.URL {
+	endpoint := *graphql
+
+	switch {
+	case endpoint.Host == githubAPIHost:
+		endpoint.Path = ""
+	case strings.HasSuffix(endpoint.Path, "/api/graphql"):
+		endpoint.Path = strings.TrimSuffix(endpoint.Path, "/graphql") + "/v3"
+	default:
+		endpoint.Path = strings.TrimSuffix(endpoint.Path, "/graphql")
+	}
+
+	return &endpoint
+}
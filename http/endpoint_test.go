@@ -0,0 +1,67 @@
+package http
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/smartystreets/gunit"
+)
+
+func TestEndpoint(t *testing.T) {
+	gunit.Run(new(EndpointFixture), t)
+}
+
+type EndpointFixture struct {
+	*gunit.Fixture
+}
+
+func (ef *EndpointFixture) TestEmptyHostIsGithub() {
+	ef.assertEndpoint("", "https://api.github.com/graphql")
+}
+
+func (ef *EndpointFixture) TestGithubHosts() {
+	ef.assertEndpoint("github.com", "https://api.github.com/graphql")
+	ef.assertEndpoint("api.github.com", "https://api.github.com/graphql")
+	ef.assertEndpoint("https://github.com/", "https://api.github.com/graphql")
+}
+
+func (ef *EndpointFixture) TestEnterpriseHost() {
+	ef.assertEndpoint("github.example.com", "https://github.example.com/api/graphql")
+	ef.assertEndpoint("https://github.example.com/", "https://github.example.com/api/graphql")
+}
+
+func (ef *EndpointFixture) TestPlainHTTPHostWithPort() {
+	ef.assertEndpoint("http://localhost:8080", "http://localhost:8080/api/graphql")
+}
+
+func (ef *EndpointFixture) TestExplicitPathKept() {
+	ef.assertEndpoint("http://127.0.0.1:9000/graphql", "http://127.0.0.1:9000/graphql")
+}
+
+func (ef *EndpointFixture) TestInvalidEndpoint() {
+	for _, host := range []string{"ftp://github.example.com", "https://", "http://local host"} {
+		_, err := ParseEndpoint(host)
+		ef.So(errors.Is(err, ErrEndpoint), should.BeTrue)
+	}
+}
+
+func (ef *EndpointFixture) TestRESTEndpoint() {
+	ef.assertRESTEndpoint("", "https://api.github.com")
+	ef.assertRESTEndpoint("github.example.com", "https://github.example.com/api/v3")
+	ef.assertRESTEndpoint("http://127.0.0.1:9000/graphql", "http://127.0.0.1:9000")
+}
+
+func (ef *EndpointFixture) assertRESTEndpoint(host string, expected string) {
+	endpoint, _ := ParseEndpoint(host)
+
+	ef.So(RESTEndpoint(endpoint).String(), should.Equal, expected)
+}
+
+func (ef *EndpointFixture) assertEndpoint(host string, expected string) {
+	endpoint, err := ParseEndpoint(host)
+
+	ef.So(err, should.BeNil)
+	ef.So(endpoint.String(), should.Equal, expected)
+}
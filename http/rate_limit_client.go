@@ -0,0 +1,231 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+)
+
+func NewRateLimitClient(inner Client) *RateLimitClient {
+	return &RateLimitClient{
+		inner:     inner,
+		remaining: -1,
+		now:       time.Now,
+		sleep:     sleepContext,
+	}
+}
+
+// RateLimitClient tracks the GitHub rate limit window and blocks outgoing requests
+// until the window resets once the remaining budget is exhausted. A rate limited
+// request, a 403/429 or a GraphQL RATE_LIMITED error returned with status 200, is
+// sent again after the reset.
+type RateLimitClient struct {
+	inner     Client
+	mutex     sync.Mutex
+	remaining int
+	reset     time.Time
+	now       func() time.Time
+	sleep     func(ctx context.Context, duration time.Duration) error
+}
+
+func (rl *RateLimitClient) Do(request *http.Request) (*http.Response, error) {
+	if err := rl.wait(request.Context()); nil != err {
+		return nil, err
+	}
+
+	response, err := rl.inner.Do(request)
+	if nil != err {
+		return response, err
+	}
+
+	rl.update(response)
+
+	if !rl.isRateLimited(response) || nil == request.GetBody {
+		return response, nil
+	}
+
+	return rl.retry(request, response)
+}
+
+// Remaining returns the last known remaining budget, or -1 when it is unknown.
+func (rl *RateLimitClient) Remaining() int {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	return rl.remaining
+}
+
+func (rl *RateLimitClient) retry(request *http.Request, response *http.Response) (*http.Response, error) {
+	response.Body.Close()
+
+	if err := rl.wait(request.Context()); nil != err {
+		return nil, err
+	}
+
+	body, err := request.GetBody()
+	if nil != err {
+		return nil, err
+	}
+	request.Body = body
+
+	response, err = rl.inner.Do(request)
+	if nil != err {
+		return response, err
+	}
+
+	rl.update(response)
+
+	return response, nil
+}
+
+func (rl *RateLimitClient) wait(ctx context.Context) error {
+	rl.mutex.Lock()
+	exhausted := rl.remaining == 0
+	delay := rl.reset.Sub(rl.now())
+	rl.mutex.Unlock()
+
+	if !exhausted || delay <= 0 {
+		return nil
+	}
+
+	return rl.sleep(ctx, delay)
+}
+
+func (rl *RateLimitClient) isRateLimited(response *http.Response) bool {
+	switch response.StatusCode {
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		return rl.Remaining() == 0
+	case http.StatusOK:
+		if !isGraphQLRateLimited(response) {
+			return false
+		}
+
+		// The budget is spent, whatever the headers say: wait for the reset.
+		rl.mutex.Lock()
+		rl.remaining = 0
+		rl.mutex.Unlock()
+
+		return true
+	}
+
+	return false
+}
+
+// isGraphQLRateLimited tells whether the response reports a RATE_LIMITED GraphQL
+// error, which github returns with status 200. The body is restored so the caller
+// can still decode it.
+func isGraphQLRateLimited(response *http.Response) bool {
+	if response.StatusCode != http.StatusOK || nil == response.Body {
+		return false
+	}
+
+	content, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	response.Body = ioutil.NopCloser(bytes.NewReader(content))
+
+	if nil != err {
+		return false
+	}
+
+	result := &struct {
+		Errors []struct {
+			Type string `json:"type"`
+		} `json:"errors"`
+	}{}
+
+	if err := json.Unmarshal(content, result); nil != err {
+		return false
+	}
+
+	for _, graphQLErr := range result.Errors {
+		if graphQLErr.Type == "RATE_LIMITED" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (rl *RateLimitClient) update(response *http.Response) {
+	remaining, reset, found := readRateLimitHeaders(response.Header)
+	if bodyRemaining, bodyReset, bodyFound := rl.readBody(response); bodyFound {
+		remaining, reset, found = bodyRemaining, bodyReset, true
+	}
+
+	if !found {
+		return
+	}
+
+	rl.mutex.Lock()
+	rl.remaining = remaining
+	rl.reset = reset
+	rl.mutex.Unlock()
+}
+
+func readRateLimitHeaders(header http.Header) (int, time.Time, bool) {
+	remaining, err := strconv.Atoi(header.Get(headerRateLimitRemaining))
+	if nil != err {
+		return 0, time.Time{}, false
+	}
+
+	reset, err := strconv.ParseInt(header.Get(headerRateLimitReset), 10, 64)
+	if nil != err {
+		return 0, time.Time{}, false
+	}
+
+	return remaining, time.Unix(reset, 0), true
+}
+
+// readBody looks for the GraphQL rateLimit object in the response and restores
+// the body so the caller can still decode it.
+func (rl *RateLimitClient) readBody(response *http.Response) (int, time.Time, bool) {
+	if nil == response.Body {
+		return 0, time.Time{}, false
+	}
+
+	content, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	response.Body = ioutil.NopCloser(bytes.NewReader(content))
+
+	if nil != err {
+		return 0, time.Time{}, false
+	}
+
+	result := &rateLimitResponse{}
+	if err := json.Unmarshal(content, result); nil != err || nil == result.Data.RateLimit {
+		return 0, time.Time{}, false
+	}
+
+	return result.Data.RateLimit.Remaining, result.Data.RateLimit.ResetAt, true
+}
+
+type rateLimitResponse struct {
+	Data struct {
+		RateLimit *struct {
+			Remaining int       `json:"remaining"`
+			ResetAt   time.Time `json:"resetAt"`
+		} `json:"rateLimit"`
+	} `json:"data"`
+}
+
+func sleepContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,211 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/smartystreets/gunit"
+)
+
+func TestRateLimitClient(t *testing.T) {
+	gunit.Run(new(RateLimitClientFixture), t)
+}
+
+type RateLimitClientFixture struct {
+	*gunit.Fixture
+
+	inner  *FakeSequenceHTTPClient
+	client *RateLimitClient
+	now    time.Time
+	slept  []time.Duration
+}
+
+func (rlf *RateLimitClientFixture) Setup() {
+	rlf.now = time.Unix(1600000000, 0)
+	rlf.inner = &FakeSequenceHTTPClient{}
+	rlf.client = NewRateLimitClient(rlf.inner)
+	rlf.client.now = func() time.Time { return rlf.now }
+	rlf.client.sleep = func(ctx context.Context, duration time.Duration) error {
+		rlf.slept = append(rlf.slept, duration)
+		return ctx.Err()
+	}
+}
+
+func (rlf *RateLimitClientFixture) TestRemainingUnknownBeforeFirstResponse() {
+	rlf.So(rlf.client.Remaining(), should.Equal, -1)
+}
+
+func (rlf *RateLimitClientFixture) TestHeadersUpdateRemaining() {
+	rlf.inner.Add(http.StatusOK, `{}`, rateLimitHeader("42", "1600000100"))
+
+	response, err := rlf.client.Do(rlf.newRequest())
+
+	rlf.So(err, should.BeNil)
+	rlf.So(response.StatusCode, should.Equal, http.StatusOK)
+	rlf.So(rlf.client.Remaining(), should.Equal, 42)
+	rlf.So(rlf.slept, should.BeEmpty)
+}
+
+func (rlf *RateLimitClientFixture) TestBlocksUntilResetWhenExhausted() {
+	rlf.inner.Add(http.StatusOK, `{}`, rateLimitHeader("0", "1600000100"))
+	rlf.inner.Add(http.StatusOK, `{}`, rateLimitHeader("5000", "1600003600"))
+
+	rlf.client.Do(rlf.newRequest())
+	rlf.client.Do(rlf.newRequest())
+
+	rlf.So(rlf.slept, should.Resemble, []time.Duration{100 * time.Second})
+	rlf.So(rlf.inner.callNr, should.Equal, 2)
+	rlf.So(rlf.client.Remaining(), should.Equal, 5000)
+}
+
+func (rlf *RateLimitClientFixture) TestNoWaitWhenResetAlreadyPassed() {
+	rlf.inner.Add(http.StatusOK, `{}`, rateLimitHeader("0", "1599999000"))
+	rlf.inner.Add(http.StatusOK, `{}`, nil)
+
+	rlf.client.Do(rlf.newRequest())
+	rlf.client.Do(rlf.newRequest())
+
+	rlf.So(rlf.slept, should.BeEmpty)
+}
+
+func (rlf *RateLimitClientFixture) TestGraphQLRateLimitObjectRead() {
+	body := `{"data":{"rateLimit":{"remaining":0,"resetAt":"2020-09-13T12:27:40Z"}}}`
+	rlf.inner.Add(http.StatusOK, body, rateLimitHeader("10", "1600000100"))
+	rlf.inner.Add(http.StatusOK, `{}`, nil)
+
+	response, _ := rlf.client.Do(rlf.newRequest())
+	content, _ := ioutil.ReadAll(response.Body)
+	rlf.client.Do(rlf.newRequest())
+
+	rlf.So(string(content), should.Equal, body)
+	rlf.So(rlf.slept, should.Resemble, []time.Duration{time.Minute})
+}
+
+func (rlf *RateLimitClientFixture) TestRateLimitedResponseRetriedAfterReset() {
+	rlf.inner.Add(http.StatusForbidden, `{"message":"API rate limit exceeded"}`, rateLimitHeader("0", "1600000030"))
+	rlf.inner.Add(http.StatusOK, `{"data":{}}`, rateLimitHeader("4999", "1600003600"))
+
+	response, err := rlf.client.Do(rlf.newRequest())
+	content, _ := ioutil.ReadAll(response.Body)
+
+	rlf.So(err, should.BeNil)
+	rlf.So(response.StatusCode, should.Equal, http.StatusOK)
+	rlf.So(string(content), should.Equal, `{"data":{}}`)
+	rlf.So(rlf.slept, should.Resemble, []time.Duration{30 * time.Second})
+	rlf.So(rlf.inner.bodies, should.Resemble, []string{"query", "query"})
+}
+
+func (rlf *RateLimitClientFixture) TestGraphQLRateLimitedErrorRetriedAfterReset() {
+	rlf.inner.Add(http.StatusOK, `{"errors":[{"type":"RATE_LIMITED","message":"API rate limit exceeded"}]}`, rateLimitHeader("0", "1600000030"))
+	rlf.inner.Add(http.StatusOK, `{"data":{}}`, rateLimitHeader("4999", "1600003600"))
+
+	response, err := rlf.client.Do(rlf.newRequest())
+	content, _ := ioutil.ReadAll(response.Body)
+
+	rlf.So(err, should.BeNil)
+	rlf.So(string(content), should.Equal, `{"data":{}}`)
+	rlf.So(rlf.slept, should.Resemble, []time.Duration{30 * time.Second})
+	rlf.So(rlf.inner.bodies, should.Resemble, []string{"query", "query"})
+	rlf.So(rlf.client.Remaining(), should.Equal, 4999)
+}
+
+func (rlf *RateLimitClientFixture) TestOtherGraphQLErrorsNotRetried() {
+	body := `{"errors":[{"type":"NOT_FOUND","message":"Could not resolve to a node"}]}`
+	rlf.inner.Add(http.StatusOK, body, rateLimitHeader("10", "1600000030"))
+
+	response, _ := rlf.client.Do(rlf.newRequest())
+	content, _ := ioutil.ReadAll(response.Body)
+
+	rlf.So(string(content), should.Equal, body)
+	rlf.So(rlf.inner.callNr, should.Equal, 1)
+}
+
+func (rlf *RateLimitClientFixture) TestForbiddenWithBudgetLeftNotRetried() {
+	rlf.inner.Add(http.StatusForbidden, `{"message":"Forbidden"}`, rateLimitHeader("10", "1600000030"))
+
+	response, _ := rlf.client.Do(rlf.newRequest())
+
+	rlf.So(response.StatusCode, should.Equal, http.StatusForbidden)
+	rlf.So(rlf.inner.callNr, should.Equal, 1)
+}
+
+func (rlf *RateLimitClientFixture) TestCancelledWhileWaiting() {
+	rlf.inner.Add(http.StatusOK, `{}`, rateLimitHeader("0", "1600000100"))
+	rlf.client.Do(rlf.newRequest())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := rlf.client.Do(rlf.newRequest().WithContext(ctx))
+
+	rlf.So(errors.Is(err, context.Canceled), should.BeTrue)
+	rlf.So(rlf.inner.callNr, should.Equal, 1)
+}
+
+func (rlf *RateLimitClientFixture) TestInnerErrorReturned() {
+	rlf.inner.err = errors.New("HTTP Error")
+
+	_, err := rlf.client.Do(rlf.newRequest())
+
+	rlf.So(err.Error(), should.Equal, "HTTP Error")
+}
+
+func (rlf *RateLimitClientFixture) newRequest() *http.Request {
+	request, _ := http.NewRequest("POST", "", strings.NewReader("query"))
+
+	return request
+}
+
+func rateLimitHeader(remaining string, reset string) http.Header {
+	header := http.Header{}
+	header.Set(headerRateLimitRemaining, remaining)
+	header.Set(headerRateLimitReset, reset)
+
+	return header
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type FakeSequenceHTTPClient struct {
+	responses []*http.Response
+	bodies    []string
+	headers   []http.Header
+	err       error
+	callNr    int
+}
+
+func (fc *FakeSequenceHTTPClient) Add(statusCode int, body string, header http.Header) {
+	if nil == header {
+		header = http.Header{}
+	}
+
+	fc.responses = append(fc.responses, &http.Response{
+		StatusCode: statusCode,
+		Header:     header,
+		Body:       NewSearchReadBuffer(body),
+	})
+}
+
+func (fc *FakeSequenceHTTPClient) Do(request *http.Request) (*http.Response, error) {
+	fc.headers = append(fc.headers, request.Header.Clone())
+
+	if nil != request.Body {
+		body, _ := ioutil.ReadAll(request.Body)
+		fc.bodies = append(fc.bodies, string(body))
+	}
+
+	if nil != fc.err {
+		return nil, fc.err
+	}
+
+	response := fc.responses[fc.callNr]
+	fc.callNr++
+
+	return response, nil
+}
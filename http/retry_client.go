@@ -0,0 +1,162 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const headerRetryAfter = "Retry-After"
+
+func NewRetryClient(inner Client, maxAttempts int, baseDelay time.Duration, maxDelay time.Duration) *RetryClient {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	return &RetryClient{
+		inner:       inner,
+		maxAttempts: maxAttempts,
+		baseDelay:   baseDelay,
+		maxDelay:    maxDelay,
+		now:         time.Now,
+		random:      rand.Float64,
+		sleep:       sleepContext,
+	}
+}
+
+// RetryClient repeats requests that failed with a transient error: transport errors,
+// 502/503/504 responses and secondary ("abuse") rate limits. The delay between attempts
+// grows exponentially with jitter, unless the server asks for a delay with Retry-After.
+// A Retry-After longer than the maximum delay is not waited for: the response is
+// returned as it is.
+type RetryClient struct {
+	inner       Client
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	now         func() time.Time
+	random      func() float64
+	sleep       func(ctx context.Context, duration time.Duration) error
+}
+
+func (rc *RetryClient) Do(request *http.Request) (*http.Response, error) {
+	body, err := bufferBody(request)
+	if nil != err {
+		return nil, err
+	}
+
+	var response *http.Response
+
+	for attempt := 1; ; attempt++ {
+		replayBody(request, body)
+		response, err = rc.inner.Do(request)
+
+		if attempt >= rc.maxAttempts || !rc.isRetryable(response, err) || nil != request.Context().Err() {
+			return response, err
+		}
+
+		delay, ok := rc.delay(attempt, response)
+		if !ok {
+			return response, err
+		}
+
+		if nil != response {
+			response.Body.Close()
+		}
+
+		if err := rc.sleep(request.Context(), delay); nil != err {
+			return nil, err
+		}
+	}
+}
+
+func (rc *RetryClient) isRetryable(response *http.Response, err error) bool {
+	if nil != err {
+		return true
+	}
+
+	switch response.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		return response.Header.Get(headerRetryAfter) != "" || rc.isSecondaryRateLimit(response)
+	}
+
+	return false
+}
+
+// isSecondaryRateLimit inspects the error message of a forbidden response and restores
+// the body so it can still be read by the caller.
+func (rc *RetryClient) isSecondaryRateLimit(response *http.Response) bool {
+	content, _ := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	response.Body = ioutil.NopCloser(bytes.NewReader(content))
+
+	message := strings.ToLower(string(content))
+
+	return strings.Contains(message, "secondary rate limit") || strings.Contains(message, "abuse")
+}
+
+// delay returns the time to wait before the next attempt, false when the server
+// asks to wait longer than the maximum delay.
+func (rc *RetryClient) delay(attempt int, response *http.Response) (time.Duration, bool) {
+	if delay, ok := rc.retryAfter(response); ok {
+		return delay, delay <= rc.maxDelay
+	}
+
+	delay := rc.baseDelay << uint(attempt-1)
+	if delay > rc.maxDelay || delay <= 0 {
+		delay = rc.maxDelay
+	}
+
+	return delay/2 + time.Duration(rc.random()*float64(delay/2)), true
+}
+
+func (rc *RetryClient) retryAfter(response *http.Response) (time.Duration, bool) {
+	if nil == response {
+		return 0, false
+	}
+
+	value := response.Header.Get(headerRetryAfter)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); nil == err {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); nil == err {
+		return date.Sub(rc.now()), true
+	}
+
+	return 0, false
+}
+
+// bufferBody reads the request body so it can be sent again on every attempt.
+func bufferBody(request *http.Request) ([]byte, error) {
+	if nil == request.Body {
+		return nil, nil
+	}
+
+	defer request.Body.Close()
+
+	return ioutil.ReadAll(request.Body)
+}
+
+func replayBody(request *http.Request, body []byte) {
+	if nil == body {
+		return
+	}
+
+	request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	request.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+}
@@ -0,0 +1,235 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/smartystreets/gunit"
+)
+
+func TestRetryClient(t *testing.T) {
+	gunit.Run(new(RetryClientFixture), t)
+}
+
+type RetryClientFixture struct {
+	*gunit.Fixture
+
+	server *FlakyServer
+	client *RetryClient
+	slept  []time.Duration
+}
+
+func (rcf *RetryClientFixture) Setup() {
+	rcf.server = NewFlakyServer()
+	rcf.client = NewRetryClient(http.DefaultClient, 4, time.Second, 10*time.Second)
+	rcf.client.now = func() time.Time { return time.Date(2020, 9, 13, 12, 0, 0, 0, time.UTC) }
+	rcf.client.random = func() float64 { return 1 }
+	rcf.client.sleep = func(ctx context.Context, duration time.Duration) error {
+		rcf.slept = append(rcf.slept, duration)
+		return ctx.Err()
+	}
+}
+
+func (rcf *RetryClientFixture) Teardown() {
+	rcf.server.Close()
+}
+
+func (rcf *RetryClientFixture) TestSucceedsAfterTransientFailures() {
+	rcf.server.Fail(3, http.StatusBadGateway, nil, "<html>Bad Gateway</html>")
+
+	response, err := rcf.client.Do(rcf.newRequest())
+
+	rcf.So(err, should.BeNil)
+	rcf.So(response.StatusCode, should.Equal, http.StatusOK)
+	rcf.So(rcf.readBody(response), should.Equal, "ok")
+	rcf.So(rcf.server.Bodies(), should.Resemble, []string{"query", "query", "query", "query"})
+	rcf.So(rcf.slept, should.Resemble, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second})
+}
+
+func (rcf *RetryClientFixture) TestGivesUpAfterMaxAttempts() {
+	rcf.server.Fail(5, http.StatusServiceUnavailable, nil, "unavailable")
+
+	response, err := rcf.client.Do(rcf.newRequest())
+
+	rcf.So(err, should.BeNil)
+	rcf.So(response.StatusCode, should.Equal, http.StatusServiceUnavailable)
+	rcf.So(rcf.readBody(response), should.Equal, "unavailable")
+	rcf.So(rcf.server.Bodies(), should.HaveLength, 4)
+}
+
+func (rcf *RetryClientFixture) TestBackoffCappedAtMaxDelay() {
+	rcf.client.maxAttempts = 6
+	rcf.server.Fail(5, http.StatusGatewayTimeout, nil, "")
+
+	rcf.client.Do(rcf.newRequest())
+
+	rcf.So(rcf.slept, should.Resemble, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second})
+}
+
+func (rcf *RetryClientFixture) TestJitterBetweenHalfAndFullDelay() {
+	rcf.client.random = func() float64 { return 0 }
+	rcf.server.Fail(2, http.StatusBadGateway, nil, "")
+
+	rcf.client.Do(rcf.newRequest())
+
+	rcf.So(rcf.slept, should.Resemble, []time.Duration{500 * time.Millisecond, time.Second})
+}
+
+func (rcf *RetryClientFixture) TestRetryAfterSecondsHonoured() {
+	rcf.server.Fail(1, http.StatusForbidden, http.Header{headerRetryAfter: {"6"}}, `{"message":"You have triggered an abuse detection mechanism."}`)
+
+	response, _ := rcf.client.Do(rcf.newRequest())
+
+	rcf.So(response.StatusCode, should.Equal, http.StatusOK)
+	rcf.So(rcf.slept, should.Resemble, []time.Duration{6 * time.Second})
+}
+
+func (rcf *RetryClientFixture) TestRetryAfterOverMaxDelayNotWaited() {
+	rcf.server.Fail(1, http.StatusForbidden, http.Header{headerRetryAfter: {"3600"}}, `{"message":"You have exceeded a secondary rate limit."}`)
+
+	response, err := rcf.client.Do(rcf.newRequest())
+
+	rcf.So(err, should.BeNil)
+	rcf.So(response.StatusCode, should.Equal, http.StatusForbidden)
+	rcf.So(rcf.readBody(response), should.ContainSubstring, "secondary rate limit")
+	rcf.So(rcf.server.Bodies(), should.HaveLength, 1)
+	rcf.So(rcf.slept, should.BeEmpty)
+}
+
+func (rcf *RetryClientFixture) TestRetryAfterDateHonoured() {
+	rcf.server.Fail(1, http.StatusTooManyRequests, http.Header{headerRetryAfter: {"Sun, 13 Sep 2020 12:00:08 GMT"}}, "")
+
+	rcf.client.Do(rcf.newRequest())
+
+	rcf.So(rcf.slept, should.Resemble, []time.Duration{8 * time.Second})
+}
+
+func (rcf *RetryClientFixture) TestSecondaryRateLimitWithoutRetryAfterRetried() {
+	rcf.server.Fail(1, http.StatusForbidden, nil, `{"message":"You have exceeded a secondary rate limit."}`)
+
+	response, _ := rcf.client.Do(rcf.newRequest())
+
+	rcf.So(response.StatusCode, should.Equal, http.StatusOK)
+	rcf.So(rcf.slept, should.HaveLength, 1)
+}
+
+func (rcf *RetryClientFixture) TestForbiddenNotRetried() {
+	rcf.server.Fail(1, http.StatusForbidden, nil, `{"message":"Resource not accessible by integration"}`)
+
+	response, _ := rcf.client.Do(rcf.newRequest())
+
+	rcf.So(response.StatusCode, should.Equal, http.StatusForbidden)
+	rcf.So(rcf.readBody(response), should.Equal, `{"message":"Resource not accessible by integration"}`)
+	rcf.So(rcf.slept, should.BeEmpty)
+}
+
+func (rcf *RetryClientFixture) TestUnauthorizedNotRetried() {
+	rcf.server.Fail(1, http.StatusUnauthorized, nil, "")
+
+	response, _ := rcf.client.Do(rcf.newRequest())
+
+	rcf.So(response.StatusCode, should.Equal, http.StatusUnauthorized)
+	rcf.So(rcf.server.Bodies(), should.HaveLength, 1)
+}
+
+func (rcf *RetryClientFixture) TestTransportErrorRetried() {
+	inner := &FakeSequenceHTTPClient{err: errors.New("connection reset")}
+	rcf.client.inner = inner
+
+	_, err := rcf.client.Do(rcf.newRequest())
+
+	rcf.So(err.Error(), should.Equal, "connection reset")
+	rcf.So(inner.bodies, should.Resemble, []string{"query", "query", "query", "query"})
+}
+
+func (rcf *RetryClientFixture) TestCancelledWhileWaiting() {
+	rcf.server.Fail(1, http.StatusBadGateway, nil, "")
+	ctx, cancel := context.WithCancel(context.Background())
+	rcf.client.sleep = func(ctx context.Context, duration time.Duration) error {
+		cancel()
+		return ctx.Err()
+	}
+
+	_, err := rcf.client.Do(rcf.newRequest().WithContext(ctx))
+
+	rcf.So(errors.Is(err, context.Canceled), should.BeTrue)
+	rcf.So(rcf.server.Bodies(), should.HaveLength, 1)
+}
+
+func (rcf *RetryClientFixture) newRequest() *http.Request {
+	request, _ := http.NewRequest("POST", rcf.server.URL, strings.NewReader("query"))
+
+	return request
+}
+
+func (rcf *RetryClientFixture) readBody(response *http.Response) string {
+	defer response.Body.Close()
+	content, _ := ioutil.ReadAll(response.Body)
+
+	return string(content)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// FlakyServer fails the configured number of requests before answering "ok".
+type FlakyServer struct {
+	*httptest.Server
+
+	mutex      sync.Mutex
+	failures   int
+	statusCode int
+	header     http.Header
+	body       string
+	bodies     []string
+}
+
+func NewFlakyServer() *FlakyServer {
+	fs := &FlakyServer{}
+	fs.Server = httptest.NewServer(http.HandlerFunc(fs.handle))
+
+	return fs
+}
+
+func (fs *FlakyServer) Fail(failures int, statusCode int, header http.Header, body string) {
+	fs.failures = failures
+	fs.statusCode = statusCode
+	fs.header = header
+	fs.body = body
+}
+
+func (fs *FlakyServer) Bodies() []string {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	return fs.bodies
+}
+
+func (fs *FlakyServer) handle(writer http.ResponseWriter, request *http.Request) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	body, _ := ioutil.ReadAll(request.Body)
+	fs.bodies = append(fs.bodies, string(body))
+
+	if fs.failures > 0 {
+		fs.failures--
+		for key, values := range fs.header {
+			writer.Header()[key] = values
+		}
+		writer.WriteHeader(fs.statusCode)
+		writer.Write([]byte(fs.body))
+
+		return
+	}
+
+	writer.Write([]byte("ok"))
+}
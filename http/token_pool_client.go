@@ -0,0 +1,208 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrNoToken = errors.New("no usable token")
+
+// ParseTokens splits a comma or newline separated list of tokens. Empty entries and
+// lines starting with # are ignored.
+func ParseTokens(value string) []string {
+	var tokens []string
+
+	for _, line := range strings.Split(value, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		for _, token := range strings.Split(line, ",") {
+			if token = strings.TrimSpace(token); token != "" {
+				tokens = append(tokens, token)
+			}
+		}
+	}
+
+	return tokens
+}
+
+func NewTokenPoolClient(inner Client, tokens []string) *TokenPoolClient {
+	pool := &TokenPoolClient{
+		inner: inner,
+		now:   time.Now,
+		sleep: sleepContext,
+	}
+
+	for _, token := range tokens {
+		pool.tokens = append(pool.tokens, &pooledToken{value: token, remaining: -1})
+	}
+
+	return pool
+}
+
+// unknownResetDelay is the time a token reported as rate limited without a reset
+// time is skipped.
+const unknownResetDelay = time.Minute
+
+// TokenPoolClient authenticates every request with the token that has the most
+// remaining quota. Tokens rejected with 401 are dropped from the pool, exhausted
+// tokens, answered with 403/429 or a GraphQL RATE_LIMITED error, are skipped until
+// their window resets.
+type TokenPoolClient struct {
+	inner  Client
+	mutex  sync.Mutex
+	tokens []*pooledToken
+	now    func() time.Time
+	sleep  func(ctx context.Context, duration time.Duration) error
+}
+
+type pooledToken struct {
+	value     string
+	remaining int
+	reset     time.Time
+	revoked   bool
+}
+
+func (tp *TokenPoolClient) Do(request *http.Request) (*http.Response, error) {
+	body, err := bufferBody(request)
+	if nil != err {
+		return nil, err
+	}
+
+	for {
+		token, err := tp.acquire(request)
+		if nil != err {
+			return nil, err
+		}
+
+		replayBody(request, body)
+		request.Header.Set("Authorization", fmt.Sprintf("bearer %s", token.value))
+
+		response, err := tp.inner.Do(request)
+		if nil != err {
+			return response, err
+		}
+
+		if !tp.release(token, response) {
+			return response, nil
+		}
+
+		response.Body.Close()
+	}
+}
+
+// acquire returns the healthiest token, waiting for the earliest reset when every
+// token in the pool is exhausted.
+func (tp *TokenPoolClient) acquire(request *http.Request) (*pooledToken, error) {
+	for {
+		token, wait := tp.healthiest()
+		if nil != token {
+			return token, nil
+		}
+
+		if wait < 0 {
+			return nil, ErrNoToken
+		}
+
+		if err := tp.sleep(request.Context(), wait); nil != err {
+			return nil, err
+		}
+	}
+}
+
+func (tp *TokenPoolClient) healthiest() (*pooledToken, time.Duration) {
+	tp.mutex.Lock()
+	defer tp.mutex.Unlock()
+
+	var (
+		best *pooledToken
+		wait = time.Duration(-1)
+		now  = tp.now()
+	)
+
+	for _, token := range tp.tokens {
+		if token.revoked {
+			continue
+		}
+
+		if token.remaining == 0 && token.reset.After(now) {
+			if delay := token.reset.Sub(now); wait < 0 || delay < wait {
+				wait = delay
+			}
+			continue
+		}
+
+		if nil == best || token.score() > best.score() {
+			best = token
+		}
+	}
+
+	return best, wait
+}
+
+// release records the quota reported by the response and tells whether the request
+// has to be repeated with another token.
+func (tp *TokenPoolClient) release(token *pooledToken, response *http.Response) bool {
+	graphQLRateLimited := isGraphQLRateLimited(response)
+
+	tp.mutex.Lock()
+	defer tp.mutex.Unlock()
+
+	if remaining, reset, ok := readRateLimitHeaders(response.Header); ok {
+		token.remaining = remaining
+		token.reset = reset
+	}
+
+	if graphQLRateLimited {
+		token.remaining = 0
+		if !token.reset.After(tp.now()) {
+			token.reset = tp.now().Add(unknownResetDelay)
+		}
+
+		return true
+	}
+
+	switch response.StatusCode {
+	case http.StatusUnauthorized:
+		token.revoked = true
+		return tp.countUsable() > 0
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		return token.remaining == 0
+	}
+
+	return false
+}
+
+// Tokens returns the number of tokens that were not rejected.
+func (tp *TokenPoolClient) Tokens() int {
+	tp.mutex.Lock()
+	defer tp.mutex.Unlock()
+
+	return tp.countUsable()
+}
+
+func (tp *TokenPoolClient) countUsable() int {
+	count := 0
+	for _, token := range tp.tokens {
+		if !token.revoked {
+			count++
+		}
+	}
+
+	return count
+}
+
+// score prefers tokens with an unknown quota so every token gets probed once.
+func (pt *pooledToken) score() int {
+	if pt.remaining < 0 {
+		return int(^uint(0) >> 1)
+	}
+
+	return pt.remaining
+}
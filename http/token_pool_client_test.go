@@ -0,0 +1,173 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/smartystreets/gunit"
+)
+
+func TestTokenPoolClient(t *testing.T) {
+	gunit.Run(new(TokenPoolClientFixture), t)
+}
+
+type TokenPoolClientFixture struct {
+	*gunit.Fixture
+
+	inner  *FakeSequenceHTTPClient
+	client *TokenPoolClient
+	now    time.Time
+	slept  []time.Duration
+}
+
+func (tpf *TokenPoolClientFixture) Setup() {
+	tpf.now = time.Unix(1600000000, 0)
+	tpf.inner = &FakeSequenceHTTPClient{}
+	tpf.client = NewTokenPoolClient(tpf.inner, []string{"token1", "token2", "token3"})
+	tpf.client.now = func() time.Time { return tpf.now }
+	tpf.client.sleep = func(ctx context.Context, duration time.Duration) error {
+		tpf.slept = append(tpf.slept, duration)
+		tpf.now = tpf.now.Add(duration)
+		return ctx.Err()
+	}
+}
+
+func (tpf *TokenPoolClientFixture) TestParseTokens() {
+	tokens := ParseTokens(" token1, token2,,\n# comment, ignored\ntoken3\n\n")
+
+	tpf.So(tokens, should.Resemble, []string{"token1", "token2", "token3"})
+}
+
+func (tpf *TokenPoolClientFixture) TestUnknownTokensProbedFirst() {
+	tpf.inner.Add(http.StatusOK, `{}`, rateLimitHeader("100", "1600003600"))
+	tpf.inner.Add(http.StatusOK, `{}`, rateLimitHeader("200", "1600003600"))
+	tpf.inner.Add(http.StatusOK, `{}`, rateLimitHeader("50", "1600003600"))
+
+	tpf.doRequests(3)
+
+	tpf.So(tpf.usedTokens(), should.Resemble, []string{"token1", "token2", "token3"})
+}
+
+func (tpf *TokenPoolClientFixture) TestHealthiestTokenUsed() {
+	tpf.inner.Add(http.StatusOK, `{}`, rateLimitHeader("100", "1600003600"))
+	tpf.inner.Add(http.StatusOK, `{}`, rateLimitHeader("200", "1600003600"))
+	tpf.inner.Add(http.StatusOK, `{}`, rateLimitHeader("50", "1600003600"))
+	tpf.inner.Add(http.StatusOK, `{}`, rateLimitHeader("199", "1600003600"))
+	tpf.inner.Add(http.StatusOK, `{}`, rateLimitHeader("198", "1600003600"))
+
+	tpf.doRequests(5)
+
+	tpf.So(tpf.usedTokens()[3:], should.Resemble, []string{"token2", "token2"})
+}
+
+func (tpf *TokenPoolClientFixture) TestUnauthorizedTokenRotatedAway() {
+	tpf.inner.Add(http.StatusUnauthorized, `{"message":"Bad credentials"}`, nil)
+	tpf.inner.Add(http.StatusOK, `{}`, rateLimitHeader("100", "1600003600"))
+	tpf.inner.Add(http.StatusOK, `{}`, rateLimitHeader("100", "1600003600"))
+	tpf.inner.Add(http.StatusOK, `{}`, rateLimitHeader("10", "1600003600"))
+
+	response, _ := tpf.client.Do(tpf.newRequest())
+	tpf.doRequests(2)
+
+	tpf.So(response.StatusCode, should.Equal, http.StatusOK)
+	tpf.So(tpf.usedTokens(), should.Resemble, []string{"token1", "token2", "token3", "token2"})
+	tpf.So(tpf.inner.bodies, should.Resemble, []string{"query", "query", "query", "query"})
+	tpf.So(tpf.client.Tokens(), should.Equal, 2)
+}
+
+func (tpf *TokenPoolClientFixture) TestLastUnauthorizedResponseReturned() {
+	tpf.client = NewTokenPoolClient(tpf.inner, []string{"token1"})
+	tpf.inner.Add(http.StatusUnauthorized, `{"message":"Bad credentials"}`, nil)
+
+	response, err := tpf.client.Do(tpf.newRequest())
+	_, err2 := tpf.client.Do(tpf.newRequest())
+
+	tpf.So(err, should.BeNil)
+	tpf.So(response.StatusCode, should.Equal, http.StatusUnauthorized)
+	tpf.So(errors.Is(err2, ErrNoToken), should.BeTrue)
+}
+
+func (tpf *TokenPoolClientFixture) TestExhaustedTokenRotatedAway() {
+	tpf.client = NewTokenPoolClient(tpf.inner, []string{"token1", "token2"})
+	tpf.inner.Add(http.StatusForbidden, `{"message":"API rate limit exceeded"}`, rateLimitHeader("0", "1600000100"))
+	tpf.inner.Add(http.StatusOK, `{}`, rateLimitHeader("10", "1600003600"))
+	tpf.inner.Add(http.StatusOK, `{}`, rateLimitHeader("9", "1600003600"))
+
+	response, _ := tpf.client.Do(tpf.newRequest())
+	tpf.doRequests(1)
+
+	tpf.So(response.StatusCode, should.Equal, http.StatusOK)
+	tpf.So(tpf.usedTokens(), should.Resemble, []string{"token1", "token2", "token2"})
+	tpf.So(tpf.slept, should.BeEmpty)
+}
+
+func (tpf *TokenPoolClientFixture) TestGraphQLRateLimitedTokenRotatedAway() {
+	tpf.client = NewTokenPoolClient(tpf.inner, []string{"token1", "token2"})
+	tpf.client.now = func() time.Time { return tpf.now }
+	tpf.inner.Add(http.StatusOK, `{"errors":[{"type":"RATE_LIMITED","message":"API rate limit exceeded"}]}`, nil)
+	tpf.inner.Add(http.StatusOK, `{"data":{}}`, rateLimitHeader("10", "1600003600"))
+	tpf.inner.Add(http.StatusOK, `{"data":{}}`, rateLimitHeader("9", "1600003600"))
+
+	response, _ := tpf.client.Do(tpf.newRequest())
+	content, _ := ioutil.ReadAll(response.Body)
+	tpf.doRequests(1)
+
+	tpf.So(string(content), should.Equal, `{"data":{}}`)
+	tpf.So(tpf.usedTokens(), should.Resemble, []string{"token1", "token2", "token2"})
+	tpf.So(tpf.slept, should.BeEmpty)
+}
+
+func (tpf *TokenPoolClientFixture) TestWaitsForEarliestResetWhenAllExhausted() {
+	tpf.client = NewTokenPoolClient(tpf.inner, []string{"token1", "token2"})
+	tpf.client.now = func() time.Time { return tpf.now }
+	tpf.client.sleep = func(ctx context.Context, duration time.Duration) error {
+		tpf.slept = append(tpf.slept, duration)
+		tpf.now = tpf.now.Add(duration)
+		return nil
+	}
+	tpf.inner.Add(http.StatusOK, `{}`, rateLimitHeader("0", "1600000300"))
+	tpf.inner.Add(http.StatusOK, `{}`, rateLimitHeader("0", "1600000100"))
+	tpf.inner.Add(http.StatusOK, `{}`, rateLimitHeader("5000", "1600003700"))
+
+	tpf.doRequests(3)
+
+	tpf.So(tpf.slept, should.Resemble, []time.Duration{100 * time.Second})
+	tpf.So(tpf.usedTokens(), should.Resemble, []string{"token1", "token2", "token2"})
+}
+
+func (tpf *TokenPoolClientFixture) TestInnerErrorReturned() {
+	tpf.inner.err = errors.New("HTTP Error")
+
+	_, err := tpf.client.Do(tpf.newRequest())
+
+	tpf.So(err.Error(), should.Equal, "HTTP Error")
+}
+
+func (tpf *TokenPoolClientFixture) doRequests(count int) {
+	for i := 0; i < count; i++ {
+		tpf.client.Do(tpf.newRequest())
+	}
+}
+
+func (tpf *TokenPoolClientFixture) usedTokens() []string {
+	var tokens []string
+
+	for _, header := range tpf.inner.headers {
+		tokens = append(tokens, strings.TrimPrefix(header.Get("Authorization"), "bearer "))
+	}
+
+	return tokens
+}
+
+func (tpf *TokenPoolClientFixture) newRequest() *http.Request {
+	request, _ := http.NewRequest("POST", "", strings.NewReader("query"))
+
+	return request
+}
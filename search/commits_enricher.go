@@ -0,0 +1,107 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// CommitActivity is the number of commits on the default branch of a repository
+// in the last 30, 90 and 365 days.
+type CommitActivity struct {
+	Commits30    int64
+	Commits90    int64
+	Commits365   int64
+	LastCommitAt time.Time
+}
+
+// NewCommitsEnricher returns the enricher counting the commits of the windows
+// ending at now.
+func NewCommitsEnricher(now time.Time) *CommitsEnricher {
+	ce := &CommitsEnricher{now: now}
+	ce.fields = []*Field{
+		{"Commits30Days", "", func(r *Repository) string { return strconv.FormatInt(r.CommitActivity.Commits30, 10) }, noCommitActivity},
+		{"Commits90Days", "", func(r *Repository) string { return strconv.FormatInt(r.CommitActivity.Commits90, 10) }, noCommitActivity},
+		{"Commits365Days", "", func(r *Repository) string { return strconv.FormatInt(r.CommitActivity.Commits365, 10) }, noCommitActivity},
+		{"LastCommitDate", "", func(r *Repository) string { return formatTime(r.CommitActivity.LastCommitAt) }, noCommitActivity},
+	}
+
+	return ce
+}
+
+// CommitsEnricher fetches the commit activity of the default branch. The commits
+// are counted on the branch history, unlike updatedAt which changes with every
+// star or issue.
+type CommitsEnricher struct {
+	now    time.Time
+	fields []*Field
+}
+
+func (ce *CommitsEnricher) Name() string {
+	return "commits"
+}
+
+func (ce *CommitsEnricher) Fields() []*Field {
+	return ce.fields
+}
+
+func (ce *CommitsEnricher) Selection() string {
+	return "commitActivity: defaultBranchRef { target { ... on Commit { committedDate " +
+		"commits30: history(since: $since30) { totalCount } " +
+		"commits90: history(since: $since90) { totalCount } " +
+		"commits365: history(since: $since365) { totalCount } } } }"
+}
+
+// Variables returns the start of the windows, which move with now.
+func (ce *CommitsEnricher) Variables() []Variable {
+	return []Variable{
+		{"since30", "GitTimestamp!", ce.since(30)},
+		{"since90", "GitTimestamp!", ce.since(90)},
+		{"since365", "GitTimestamp!", ce.since(365)},
+	}
+}
+
+func (ce *CommitsEnricher) since(days int) string {
+	return ce.now.AddDate(0, 0, -days).UTC().Format(time.RFC3339)
+}
+
+// Enrich reads the commit activity. A repository without commits, which has no
+// default branch, has no activity.
+func (ce *CommitsEnricher) Enrich(repository *Repository, node json.RawMessage) error {
+	result := &struct {
+		CommitActivity *struct {
+			Target struct {
+				CommittedDate time.Time  `json:"committedDate"`
+				Commits30     totalCount `json:"commits30"`
+				Commits90     totalCount `json:"commits90"`
+				Commits365    totalCount `json:"commits365"`
+			} `json:"target"`
+		} `json:"commitActivity"`
+	}{}
+
+	if err := json.Unmarshal(node, result); nil != err {
+		return fmt.Errorf("commits of %s, %s: %w", repository.NameWithOwner, err.Error(), ErrRead)
+	}
+
+	repository.CommitActivity = &CommitActivity{}
+	if nil == result.CommitActivity {
+		return nil
+	}
+
+	target := result.CommitActivity.Target
+	repository.CommitActivity.Commits30 = target.Commits30.TotalCount
+	repository.CommitActivity.Commits90 = target.Commits90.TotalCount
+	repository.CommitActivity.Commits365 = target.Commits365.TotalCount
+	repository.CommitActivity.LastCommitAt = target.CommittedDate
+
+	return nil
+}
+
+type totalCount struct {
+	TotalCount int64 `json:"totalCount"`
+}
+
+func noCommitActivity(repository *Repository) bool {
+	return nil == repository.CommitActivity
+}
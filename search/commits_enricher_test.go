@@ -0,0 +1,72 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/smartystreets/gunit"
+)
+
+func TestCommitsEnricherFixture(t *testing.T) {
+	gunit.Run(new(CommitsEnricherFixture), t)
+}
+
+type CommitsEnricherFixture struct {
+	*gunit.Fixture
+
+	enricher   *CommitsEnricher
+	repository *Repository
+}
+
+func (cef *CommitsEnricherFixture) Setup() {
+	cef.enricher = NewCommitsEnricher(time.Date(2021, 4, 1, 12, 0, 0, 0, time.UTC))
+	cef.repository = &Repository{NameWithOwner: "owner/cli"}
+}
+
+func (cef *CommitsEnricherFixture) TestSelectionCountsTheWindows() {
+	selection := cef.enricher.Selection()
+
+	cef.So(selection, should.StartWith, "commitActivity: defaultBranchRef { target { ... on Commit { committedDate ")
+	cef.So(selection, should.ContainSubstring, `commits30: history(since: $since30) { totalCount }`)
+	cef.So(selection, should.ContainSubstring, `commits90: history(since: $since90) { totalCount }`)
+	cef.So(selection, should.ContainSubstring, `commits365: history(since: $since365) { totalCount }`)
+	cef.So(cef.enricher.Variables(), should.Resemble, []Variable{
+		{"since30", "GitTimestamp!", "2021-03-02T12:00:00Z"},
+		{"since90", "GitTimestamp!", "2021-01-01T12:00:00Z"},
+		{"since365", "GitTimestamp!", "2020-04-01T12:00:00Z"},
+	})
+}
+
+func (cef *CommitsEnricherFixture) TestActivityRead() {
+	err := cef.enricher.Enrich(cef.repository, []byte(commitsNode))
+
+	cef.So(err, should.BeNil)
+	cef.So(cef.repository.CommitActivity, should.Resemble, &CommitActivity{
+		Commits30:    12,
+		Commits90:    40,
+		Commits365:   151,
+		LastCommitAt: time.Date(2021, 3, 30, 8, 15, 0, 0, time.UTC),
+	})
+	cef.So(fieldValues(cef.enricher, cef.repository), should.Resemble, []string{"12", "40", "151", "2021-03-30 08:15:00 +0000 UTC"})
+}
+
+func (cef *CommitsEnricherFixture) TestRepositoryWithoutCommits() {
+	err := cef.enricher.Enrich(cef.repository, []byte(`{"commitActivity": null}`))
+
+	cef.So(err, should.BeNil)
+	cef.So(fieldValues(cef.enricher, cef.repository), should.Resemble, []string{"0", "0", "0", ""})
+	cef.So(cef.enricher.Fields()[0].null(cef.repository), should.BeFalse)
+}
+
+const commitsNode = `{
+    "commitActivity": {
+        "target": {
+            "committedDate": "2021-03-30T08:15:00Z",
+            "commits30": {"totalCount": 12},
+            "commits90": {"totalCount": 40},
+            "commits365": {"totalCount": 151}
+        }
+    }
+}`
@@ -0,0 +1,134 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// MaxHistoryDepth is the most commits read for the contributors, the size of one
+// page of the history. The history is not paginated: the contributors are the
+// recent authors, not every author of the repository.
+const MaxHistoryDepth = 100
+
+// DefaultHistoryDepth is the number of commits read for the contributors by
+// default.
+const DefaultHistoryDepth = 100
+
+// Contributors are the authors of the latest commits on the default branch of a
+// repository, at most the history depth of them.
+type Contributors struct {
+	Count     int
+	BusFactor int
+	Commits   int
+}
+
+// NewContributorsEnricher returns the enricher reading the authors of the latest
+// depth commits, at most MaxHistoryDepth.
+func NewContributorsEnricher(depth int) *ContributorsEnricher {
+	if depth < 1 || depth > MaxHistoryDepth {
+		depth = DefaultHistoryDepth
+	}
+
+	ce := &ContributorsEnricher{depth: depth}
+	ce.fields = []*Field{
+		{"RecentAuthors", "", func(r *Repository) string { return strconv.Itoa(r.Contributors.Count) }, noContributors},
+		{"BusFactor", "", func(r *Repository) string { return strconv.Itoa(r.Contributors.BusFactor) }, noContributors},
+	}
+
+	return ce
+}
+
+// ContributorsEnricher fetches the authors of the recent commits and computes the
+// bus factor: the least number of authors who wrote half of the commits.
+type ContributorsEnricher struct {
+	depth  int
+	fields []*Field
+}
+
+func (ce *ContributorsEnricher) Name() string {
+	return "contributors"
+}
+
+func (ce *ContributorsEnricher) Fields() []*Field {
+	return ce.fields
+}
+
+func (ce *ContributorsEnricher) Selection() string {
+	return fmt.Sprintf("contributorHistory: defaultBranchRef { target { ... on Commit { history(first: %d) { nodes { author { email name user { login } } } } } } }", ce.depth)
+}
+
+// Enrich reads the authors of the commits. An author is identified by the login
+// of the github user, by the email or the name for the commits of no user.
+func (ce *ContributorsEnricher) Enrich(repository *Repository, node json.RawMessage) error {
+	result := &struct {
+		ContributorHistory *struct {
+			Target struct {
+				History struct {
+					Nodes []struct {
+						Author struct {
+							Email string `json:"email"`
+							Name  string `json:"name"`
+							User  *struct {
+								Login string `json:"login"`
+							} `json:"user"`
+						} `json:"author"`
+					} `json:"nodes"`
+				} `json:"history"`
+			} `json:"target"`
+		} `json:"contributorHistory"`
+	}{}
+
+	if err := json.Unmarshal(node, result); nil != err {
+		return fmt.Errorf("contributors of %s, %s: %w", repository.NameWithOwner, err.Error(), ErrRead)
+	}
+
+	commits := map[string]int{}
+
+	if nil != result.ContributorHistory {
+		for _, commit := range result.ContributorHistory.Target.History.Nodes {
+			author := commit.Author.Email
+			if nil != commit.Author.User {
+				author = commit.Author.User.Login
+			} else if author == "" {
+				author = commit.Author.Name
+			}
+
+			commits[author]++
+		}
+	}
+
+	repository.Contributors = newContributors(commits)
+
+	return nil
+}
+
+// newContributors counts the authors and the bus factor of the commits by author.
+func newContributors(commits map[string]int) *Contributors {
+	contributors := &Contributors{Count: len(commits)}
+
+	counts := make([]int, 0, len(commits))
+	for _, count := range commits {
+		counts = append(counts, count)
+		contributors.Commits += count
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+
+	covered := 0
+	for _, count := range counts {
+		if covered*2 >= contributors.Commits {
+			break
+		}
+
+		covered += count
+		contributors.BusFactor++
+	}
+
+	return contributors
+}
+
+func noContributors(repository *Repository) bool {
+	return nil == repository.Contributors
+}
@@ -0,0 +1,77 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/smartystreets/gunit"
+)
+
+func TestContributorsEnricherFixture(t *testing.T) {
+	gunit.Run(new(ContributorsEnricherFixture), t)
+}
+
+type ContributorsEnricherFixture struct {
+	*gunit.Fixture
+
+	enricher   *ContributorsEnricher
+	repository *Repository
+}
+
+func (cef *ContributorsEnricherFixture) Setup() {
+	cef.enricher = NewContributorsEnricher(50)
+	cef.repository = &Repository{NameWithOwner: "owner/cli"}
+}
+
+func (cef *ContributorsEnricherFixture) TestSelectionReadsTheHistoryDepth() {
+	cef.So(cef.enricher.Selection(), should.ContainSubstring, "history(first: 50) { nodes { author { email name user { login } } } }")
+}
+
+func (cef *ContributorsEnricherFixture) TestInvalidDepthReadsDefault() {
+	cef.So(NewContributorsEnricher(0).Selection(), should.ContainSubstring, "history(first: 100)")
+	cef.So(NewContributorsEnricher(MaxHistoryDepth+1).Selection(), should.ContainSubstring, "history(first: 100)")
+}
+
+func (cef *ContributorsEnricherFixture) TestAuthorsCounted() {
+	err := cef.enricher.Enrich(cef.repository, []byte(contributorsNode))
+
+	cef.So(err, should.BeNil)
+	cef.So(cef.repository.Contributors, should.Resemble, &Contributors{Count: 4, BusFactor: 2, Commits: 7})
+	cef.So(cef.enricher.Fields()[0].Name, should.Equal, "RecentAuthors")
+	cef.So(fieldValues(cef.enricher, cef.repository), should.Resemble, []string{"4", "2"})
+}
+
+func (cef *ContributorsEnricherFixture) TestBusFactor() {
+	cef.So(newContributors(map[string]int{"a": 5}).BusFactor, should.Equal, 1)
+	cef.So(newContributors(map[string]int{"a": 5, "b": 5}).BusFactor, should.Equal, 1)
+	cef.So(newContributors(map[string]int{"a": 4, "b": 3, "c": 3}).BusFactor, should.Equal, 2)
+	cef.So(newContributors(map[string]int{"a": 1, "b": 1, "c": 1, "d": 1, "e": 1}).BusFactor, should.Equal, 3)
+	cef.So(newContributors(map[string]int{}).BusFactor, should.Equal, 0)
+}
+
+func (cef *ContributorsEnricherFixture) TestRepositoryWithoutCommits() {
+	err := cef.enricher.Enrich(cef.repository, []byte(`{"contributorHistory": null}`))
+
+	cef.So(err, should.BeNil)
+	cef.So(cef.repository.Contributors, should.Resemble, &Contributors{})
+	cef.So(cef.enricher.Fields()[0].null(cef.repository), should.BeFalse)
+}
+
+const contributorsNode = `{
+    "contributorHistory": {
+        "target": {
+            "history": {
+                "nodes": [
+                    {"author": {"email": "ann@example.com", "name": "Ann", "user": {"login": "ann"}}},
+                    {"author": {"email": "ann@work.example.com", "name": "Ann", "user": {"login": "ann"}}},
+                    {"author": {"email": "ann@example.com", "name": "Ann", "user": {"login": "ann"}}},
+                    {"author": {"email": "bob@example.com", "name": "Bob", "user": null}},
+                    {"author": {"email": "bob@example.com", "name": "Bob", "user": null}},
+                    {"author": {"email": "", "name": "build bot", "user": null}},
+                    {"author": {"email": "carl@example.com", "name": "Carl", "user": {"login": "carl"}}}
+                ]
+            }
+        }
+    }
+}`
@@ -6,26 +6,44 @@ type Response struct {
 	Data struct {
 		Search struct {
 			RepositoryCount int `json:"repositoryCount"`
-			Edges           []struct {
+			PageInfo        struct {
+				HasNextPage bool   `json:"hasNextPage"`
+				EndCursor   string `json:"endCursor"`
+			} `json:"pageInfo"`
+			Edges []struct {
 				Cursor string     `json:"cursor"`
 				Node   Repository `json:"node"`
 			} `json:"edges"`
 		} `json:"search"`
+		RateLimit RateLimit `json:"rateLimit"`
 	} `json:"data,omitempty"`
-	Message string `json:"message,omitempty"`
-	Errors  []struct {
-		Type    string `json:"type"`
-		Message string `json:"message"`
-	} `json:"errors,omitempty"`
+	Message string         `json:"message,omitempty"`
+	Errors  []GraphQLError `json:"errors,omitempty"`
+}
+
+// RateLimit is the rate limit status github reports in a GraphQL response.
+type RateLimit struct {
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"resetAt"`
+}
+
+// GraphQLError is an error github reports in the body of a GraphQL response.
+type GraphQLError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
 }
 
 type Repository struct {
+	ID            string `json:"id"`
 	Description   string `json:"description"`
 	Name          string `json:"name"`
 	NameWithOwner string `json:"nameWithOwner"`
 	URL           string `json:"url"`
 	Owner         struct {
-		Login string `json:"login"`
+		Login      string `json:"login"`
+		Typename   string `json:"__typename"`
+		URL        string `json:"url"`
+		IsVerified bool   `json:"isVerified"`
 	} `json:"owner"`
 	ForkCount  int64 `json:"forkCount"`
 	Stargazers struct {
@@ -34,21 +52,83 @@ type Repository struct {
 	Watchers struct {
 		TotalCount int64 `json:"totalCount"`
 	} `json:"watchers"`
-	HomepageURL string `json:"homepageUrl"`
-	LicenseInfo struct {
-		Name string `json:"name"`
-	} `json:"licenseInfo"`
+	HomepageURL      string   `json:"homepageUrl"`
+	LicenseInfo      *License `json:"licenseInfo"`
 	MentionableUsers struct {
 		TotalCount int64 `json:"totalCount"`
 	} `json:"mentionableUsers"`
-	MirrorURL       string `json:"mirrorUrl"`
-	IsMirror        bool   `json:"isMirror"`
-	PrimaryLanguage struct {
-		Name string `json:"name"`
-	} `json:"primaryLanguage"`
-	Parent struct {
+	MirrorURL        string            `json:"mirrorUrl"`
+	IsMirror         bool              `json:"isMirror"`
+	PrimaryLanguage  *Language         `json:"primaryLanguage"`
+	Parent           *ParentRepository `json:"parent"`
+	CreatedAt        time.Time         `json:"createdAt"`
+	UpdatedAt        time.Time         `json:"updatedAt"`
+	PushedAt         time.Time         `json:"pushedAt"`
+	RepositoryTopics struct {
+		Nodes []struct {
+			Topic struct {
+				Name string `json:"name"`
+			} `json:"topic"`
+		} `json:"nodes"`
+	} `json:"repositoryTopics"`
+	IsArchived       bool  `json:"isArchived"`
+	IsFork           bool  `json:"isFork"`
+	IsTemplate       bool  `json:"isTemplate"`
+	IsDisabled       bool  `json:"isDisabled"`
+	DiskUsage        int64 `json:"diskUsage"`
+	DefaultBranchRef struct {
 		Name string `json:"name"`
-	} `json:"parent"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	} `json:"defaultBranchRef"`
+	OpenIssues struct {
+		TotalCount int64 `json:"totalCount"`
+	} `json:"openIssues"`
+	OpenPullRequests struct {
+		TotalCount int64 `json:"totalCount"`
+	} `json:"openPullRequests"`
+	LatestRelease struct {
+		TagName     string    `json:"tagName"`
+		PublishedAt time.Time `json:"publishedAt"`
+	} `json:"latestRelease"`
+
+	// The data added by enrichers, nil when the repository was not enriched.
+	Languages       []LanguageShare  `json:"-"`
+	CommitActivity  *CommitActivity  `json:"-"`
+	ReleaseCadence  *ReleaseCadence  `json:"-"`
+	Contributors    *Contributors    `json:"-"`
+	Responsiveness  *Responsiveness  `json:"-"`
+	CommunityHealth *CommunityHealth `json:"-"`
+}
+
+// License is the license of a repository, nil in a Repository when github does
+// not recognise one.
+type License struct {
+	Name   string `json:"name"`
+	SpdxID string `json:"spdxId"`
+}
+
+// Language is the primary language of a repository, nil in a Repository without
+// code.
+type Language struct {
+	Name string `json:"name"`
+}
+
+// ParentRepository is the repository a fork was created from, nil in a Repository
+// which is not a fork.
+type ParentRepository struct {
+	Name          string `json:"name"`
+	NameWithOwner string `json:"nameWithOwner"`
+	URL           string `json:"url"`
+	Stargazers    struct {
+		TotalCount int64 `json:"totalCount"`
+	} `json:"stargazers"`
+}
+
+// Topics returns the names of the repository topics.
+func (r *Repository) Topics() []string {
+	topics := make([]string, len(r.RepositoryTopics.Nodes))
+	for i, node := range r.RepositoryTopics.Nodes {
+		topics[i] = node.Topic.Name
+	}
+
+	return topics
 }
@@ -0,0 +1,76 @@
+package search
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/smartystreets/gunit"
+)
+
+func TestRepositoryDecoderFixture(t *testing.T) {
+	gunit.Run(new(RepositoryDecoderFixture), t)
+}
+
+type RepositoryDecoderFixture struct {
+	*gunit.Fixture
+}
+
+func (rdf *RepositoryDecoderFixture) TestNullLicense() {
+	repository := rdf.decode(`{"name": "repo", "licenseInfo": null}`)
+
+	rdf.So(repository.LicenseInfo, should.BeNil)
+	rdf.So(noLicense(repository), should.BeTrue)
+}
+
+func (rdf *RepositoryDecoderFixture) TestNullPrimaryLanguage() {
+	repository := rdf.decode(`{"name": "repo", "primaryLanguage": null}`)
+
+	rdf.So(repository.PrimaryLanguage, should.BeNil)
+	rdf.So(noLanguage(repository), should.BeTrue)
+}
+
+func (rdf *RepositoryDecoderFixture) TestNullParent() {
+	repository := rdf.decode(`{"name": "repo", "parent": null}`)
+
+	rdf.So(repository.Parent, should.BeNil)
+	rdf.So(noParent(repository), should.BeTrue)
+}
+
+func (rdf *RepositoryDecoderFixture) TestMissingFieldsAreNull() {
+	repository := rdf.decode(`{"name": "repo"}`)
+
+	rdf.So(repository.LicenseInfo, should.BeNil)
+	rdf.So(repository.PrimaryLanguage, should.BeNil)
+	rdf.So(repository.Parent, should.BeNil)
+}
+
+func (rdf *RepositoryDecoderFixture) TestPresentValues() {
+	repository := rdf.decode(`{
+		"licenseInfo": {"name": "MIT License", "spdxId": "MIT"},
+		"primaryLanguage": {"name": "Go"},
+		"parent": {"name": "react", "nameWithOwner": "facebook/react", "url": "https://github.com/facebook/react", "stargazers": {"totalCount": 200000}}
+	}`)
+
+	rdf.So(repository.LicenseInfo, should.Resemble, &License{Name: "MIT License", SpdxID: "MIT"})
+	rdf.So(repository.PrimaryLanguage, should.Resemble, &Language{Name: "Go"})
+	rdf.So(repository.Parent.NameWithOwner, should.Equal, "facebook/react")
+	rdf.So(repository.Parent.Stargazers.TotalCount, should.Equal, 200000)
+	rdf.So(noLicense(repository) || noLanguage(repository) || noParent(repository), should.BeFalse)
+}
+
+func (rdf *RepositoryDecoderFixture) TestEmptyValuesAreNotNull() {
+	repository := rdf.decode(`{"licenseInfo": {"name": ""}, "primaryLanguage": {"name": ""}, "parent": {}}`)
+
+	rdf.So(repository.LicenseInfo, should.NotBeNil)
+	rdf.So(repository.PrimaryLanguage, should.NotBeNil)
+	rdf.So(repository.Parent, should.NotBeNil)
+}
+
+func (rdf *RepositoryDecoderFixture) decode(content string) *Repository {
+	repository := &Repository{}
+	rdf.So(json.Unmarshal([]byte(content), repository), should.BeNil)
+
+	return repository
+}
@@ -0,0 +1,66 @@
+package search
+
+import "encoding/json"
+
+// Enricher adds data to repositories which the search query does not fetch. The
+// selection is queried on the Repository node of every repository, Enrich reads
+// the result back into the repository.
+type Enricher interface {
+	// Name identifies the enrichment, e.g. on the command line.
+	Name() string
+	// Fields returns the fields written for the enrichment. The same fields are
+	// returned on every call.
+	Fields() []*Field
+	// Selection returns the GraphQL selection on a Repository.
+	Selection() string
+	// Enrich reads the node returned for the selection into the repository.
+	Enrich(repository *Repository, node json.RawMessage) error
+}
+
+// VariableEnricher is an Enricher whose selection uses GraphQL variables, so the
+// query text stays the same from one run to the next, e.g. for the cache.
+type VariableEnricher interface {
+	Enricher
+	// Variables returns the variables the selection uses.
+	Variables() []Variable
+}
+
+// Variable is a GraphQL variable of an enrichment query, e.g. $since30 of type
+// GitTimestamp!.
+type Variable struct {
+	Name  string
+	Type  string
+	Value interface{}
+}
+
+// EnrichersOf returns the enrichers that fetch at least one of the fields.
+func EnrichersOf(fields []*Field, enrichers []Enricher) []Enricher {
+	selected := map[*Field]bool{}
+	for _, field := range fields {
+		selected[field] = true
+	}
+
+	var used []Enricher
+
+	for _, enricher := range enrichers {
+		for _, field := range enricher.Fields() {
+			if selected[field] {
+				used = append(used, enricher)
+				break
+			}
+		}
+	}
+
+	return used
+}
+
+// FindEnricher returns the enricher with the given name, nil when there is none.
+func FindEnricher(name string, enrichers []Enricher) Enricher {
+	for _, enricher := range enrichers {
+		if enricher.Name() == name {
+			return enricher
+		}
+	}
+
+	return nil
+}
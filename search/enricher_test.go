@@ -0,0 +1,80 @@
+package search
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/smartystreets/gunit"
+)
+
+func TestEnricherFixture(t *testing.T) {
+	gunit.Run(new(EnricherFixture), t)
+}
+
+type EnricherFixture struct {
+	*gunit.Fixture
+
+	enrichers []Enricher
+}
+
+func (ef *EnricherFixture) Setup() {
+	now := time.Date(2021, 4, 1, 12, 0, 0, 0, time.UTC)
+	ef.enrichers = []Enricher{
+		NewLanguagesEnricher(),
+		NewCommitsEnricher(now),
+		NewReleasesEnricher(now),
+		NewContributorsEnricher(DefaultHistoryDepth),
+		NewResponsivenessEnricher(DefaultSampleSize),
+		NewHealthEnricher(),
+	}
+}
+
+func (ef *EnricherFixture) TestNotEnrichedIsNull() {
+	for _, enricher := range ef.enrichers {
+		for _, field := range enricher.Fields() {
+			ef.So(field.null(&Repository{}), should.BeTrue)
+		}
+	}
+}
+
+func (ef *EnricherFixture) TestSameFieldsOnEveryCall() {
+	for _, enricher := range ef.enrichers {
+		ef.So(enricher.Fields(), should.Resemble, enricher.Fields())
+		ef.So(enricher.Fields()[0], should.Equal, enricher.Fields()[0])
+	}
+}
+
+func (ef *EnricherFixture) TestInvalidNode() {
+	tests := []struct {
+		name   string
+		node   string
+		prefix string
+	}{
+		{"languages", `{"languages": []}`, "languages of owner/cli, "},
+		{"commits", `{"commitActivity": []}`, "commits of owner/cli, "},
+		{"releases", `{"releaseHistory": []}`, "releases of owner/cli, "},
+		{"contributors", `{"contributorHistory": []}`, "contributors of owner/cli, "},
+		{"responsiveness", `{"issueSample": []}`, "responsiveness of owner/cli, "},
+		{"health", `{"healthRoot": []}`, "community health of owner/cli, "},
+	}
+
+	for _, test := range tests {
+		err := FindEnricher(test.name, ef.enrichers).Enrich(&Repository{NameWithOwner: "owner/cli"}, []byte(test.node))
+
+		ef.So(errors.Is(err, ErrRead), should.BeTrue)
+		ef.So(err.Error(), should.StartWith, test.prefix)
+	}
+}
+
+// fieldValues returns the values of the fields of the enricher for the repository.
+func fieldValues(enricher Enricher, repository *Repository) []string {
+	var values []string
+	for _, field := range enricher.Fields() {
+		values = append(values, field.value(repository))
+	}
+
+	return values
+}
@@ -0,0 +1,271 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	finderhttp "github.com/vcsfrl/github-tool-finder/http"
+)
+
+// MaxBatchSize is the most node IDs github resolves in one nodes query.
+const MaxBatchSize = 100
+
+// DefaultBatchSize is the number of repositories enriched with one query by default.
+const DefaultBatchSize = 20
+
+// batchWait is the longest time a batch waits for more repositories before it is
+// enriched.
+const batchWait = 500 * time.Millisecond
+
+// RepositoryEnricher sits between the reader and the writer: it enriches the
+// repositories of the input channel in batches, one query per batch, and sends
+// them to the output channel in the order they were read.
+type RepositoryEnricher struct {
+	input     chan *Repository
+	output    chan *Repository
+	client    finderhttp.Client
+	enrichers []Enricher
+	graphQL   string
+	variables map[string]interface{}
+	batchSize int
+	wait      time.Duration
+	cancel    context.CancelFunc
+}
+
+// SetBatchSize sets the number of repositories enriched with one query, at most
+// MaxBatchSize.
+func (re *RepositoryEnricher) SetBatchSize(size int) {
+	if size > MaxBatchSize {
+		size = MaxBatchSize
+	}
+
+	if size > 0 {
+		re.batchSize = size
+	}
+}
+
+// SetCancel sets the function called when the enrichment fails, before the rest of
+// the input is sent on. It stops the reader, which then closes the input channel.
+func (re *RepositoryEnricher) SetCancel(cancel context.CancelFunc) {
+	re.cancel = cancel
+}
+
+func (re *RepositoryEnricher) Close() error {
+	close(re.output)
+
+	return nil
+}
+
+// Handle enriches the repositories until the input channel is closed, then closes
+// the output channel. When the context is cancelled or the enrichment fails, the
+// cancel function is called and the remaining repositories are sent on without
+// enrichment until the input channel is closed, so the writer still gets every
+// repository read.
+func (re *RepositoryEnricher) Handle(ctx context.Context) error {
+	defer re.Close()
+
+	for {
+		batch, open := re.receive()
+
+		if err := re.enrich(ctx, batch); nil != err {
+			re.cancel()
+			re.send(batch)
+			re.forward()
+
+			return err
+		}
+
+		re.send(batch)
+
+		if !open {
+			return ctx.Err()
+		}
+	}
+}
+
+// receive waits for a repository, then collects the batch until it is full, the
+// input channel is closed or the batch waited long enough: a batch never waits for
+// the next page of the reader, so the repositories keep streaming to the writer.
+// The reader closes the input channel when it stops, so receive does not watch the
+// context.
+func (re *RepositoryEnricher) receive() ([]*Repository, bool) {
+	repository, ok := <-re.input
+	if !ok {
+		return nil, false
+	}
+
+	batch := []*Repository{repository}
+
+	timer := time.NewTimer(re.wait)
+	defer timer.Stop()
+
+	for len(batch) < re.batchSize {
+		select {
+		case repository, ok := <-re.input:
+			if !ok {
+				return batch, false
+			}
+			batch = append(batch, repository)
+		case <-timer.C:
+			return batch, true
+		}
+	}
+
+	return batch, true
+}
+
+// send passes the repositories to the writer, which reads until the channel is
+// closed.
+func (re *RepositoryEnricher) send(batch []*Repository) {
+	for _, repository := range batch {
+		re.output <- repository
+	}
+}
+
+// forward sends the rest of the input on without enrichment.
+func (re *RepositoryEnricher) forward() {
+	for repository := range re.input {
+		re.output <- repository
+	}
+}
+
+// enrich queries the nodes of the batch. A repository github no longer finds, e.g.
+// deleted since it was read, is sent on without enrichment.
+func (re *RepositoryEnricher) enrich(ctx context.Context, batch []*Repository) error {
+	var (
+		queried []*Repository
+		ids     []string
+	)
+
+	for _, repository := range batch {
+		if repository.ID != "" {
+			queried = append(queried, repository)
+			ids = append(ids, repository.ID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	variables := map[string]interface{}{"ids": ids}
+	for name, value := range re.variables {
+		variables[name] = value
+	}
+
+	response, err := sendGraphQL(ctx, re.client, &GraphQLRequest{
+		Query:     re.graphQL,
+		Variables: variables,
+	})
+	if nil != err {
+		return err
+	}
+	defer response.Body.Close()
+
+	result := &enrichmentResponse{}
+	if err := json.NewDecoder(response.Body).Decode(result); nil != err {
+		return fmt.Errorf("%s: %w", err.Error(), ErrRead)
+	}
+
+	if err := result.error(graphQLRateLimitReset(response, result.Data.RateLimit)); nil != err {
+		return err
+	}
+
+	// The nodes come in the order of the IDs, null for the repositories not found.
+	for i, node := range result.Data.Nodes {
+		if i >= len(queried) || len(node) == 0 || string(node) == "null" {
+			continue
+		}
+
+		for _, enricher := range re.enrichers {
+			if err := enricher.Enrich(queried[i], node); nil != err {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+type enrichmentResponse struct {
+	Data struct {
+		Nodes     []json.RawMessage `json:"nodes"`
+		RateLimit RateLimit         `json:"rateLimit"`
+	} `json:"data"`
+	Message string         `json:"message,omitempty"`
+	Errors  []GraphQLError `json:"errors,omitempty"`
+}
+
+// error returns the errors of the response, except the NOT_FOUND errors of the
+// repositories that are gone.
+func (er *enrichmentResponse) error(reset time.Time) error {
+	var errors []GraphQLError
+
+	for _, err := range er.Errors {
+		if err.Type != "NOT_FOUND" {
+			errors = append(errors, err)
+		}
+	}
+
+	return responseError(er.Message, errors, reset)
+}
+
+func NewRepositoryEnricher(input chan *Repository, output chan *Repository, client finderhttp.Client, enrichers []Enricher) *RepositoryEnricher {
+	return &RepositoryEnricher{
+		input:     input,
+		output:    output,
+		client:    client,
+		enrichers: enrichers,
+		graphQL:   enrichmentQuery(enrichers),
+		variables: enrichmentVariables(enrichers),
+		batchSize: DefaultBatchSize,
+		wait:      batchWait,
+		cancel:    func() {},
+	}
+}
+
+func enrichmentQuery(enrichers []Enricher) string {
+	definitions := []string{"$ids: [ID!]!"}
+	selections := make([]string, len(enrichers))
+	for i, enricher := range enrichers {
+		selections[i] = enricher.Selection()
+
+		if variableEnricher, ok := enricher.(VariableEnricher); ok {
+			for _, variable := range variableEnricher.Variables() {
+				definitions = append(definitions, fmt.Sprintf("$%s: %s", variable.Name, variable.Type))
+			}
+		}
+	}
+
+	return fmt.Sprintf(repoEnrichmentQuery, strings.Join(definitions, ", "), strings.Join(selections, "\n      "))
+}
+
+// enrichmentVariables returns the values of the variables the enrichers use.
+func enrichmentVariables(enrichers []Enricher) map[string]interface{} {
+	variables := map[string]interface{}{}
+	for _, enricher := range enrichers {
+		if variableEnricher, ok := enricher.(VariableEnricher); ok {
+			for _, variable := range variableEnricher.Variables() {
+				variables[variable.Name] = variable.Value
+			}
+		}
+	}
+
+	return variables
+}
+
+const repoEnrichmentQuery = `query EnrichRepositories(%s) {
+  rateLimit {
+    remaining
+    resetAt
+  }
+  nodes(ids: $ids) {
+    ... on Repository {
+      %s
+    }
+  }
+}
+`
@@ -0,0 +1,285 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/smartystreets/gunit"
+)
+
+func TestRepositoryEnricherFixture(t *testing.T) {
+	gunit.Run(new(RepositoryEnricherFixture), t)
+}
+
+type RepositoryEnricherFixture struct {
+	*gunit.Fixture
+
+	fakeClient *FakeHTTPClient
+	input      chan *Repository
+	output     chan *Repository
+	enricher   *RepositoryEnricher
+}
+
+func (ref *RepositoryEnricherFixture) Setup() {
+	ref.fakeClient = &FakeHTTPClient{}
+	ref.input = make(chan *Repository, 10)
+	ref.output = make(chan *Repository, 10)
+	ref.enricher = NewRepositoryEnricher(ref.input, ref.output, ref.fakeClient, []Enricher{NewLanguagesEnricher()})
+}
+
+func (ref *RepositoryEnricherFixture) TestRepositoryEnriched() {
+	ref.fakeClient.Configure([]string{`{"data": {"nodes": [` + languagesNode + `]}}`}, 200, nil)
+	ref.input <- &Repository{ID: "R_1"}
+	close(ref.input)
+
+	err := ref.enricher.Handle(context.Background())
+	repository := <-ref.output
+	_, open := <-ref.output
+
+	ref.So(err, should.BeNil)
+	ref.So(open, should.BeFalse)
+	ref.So(repository.Languages, should.HaveLength, 4)
+	ref.So(ref.fakeClient.responseBody.closed, should.Equal, 1)
+}
+
+func (ref *RepositoryEnricherFixture) TestBatchEnrichedWithOneQuery() {
+	ref.fakeClient.Configure([]string{`{"data": {"nodes": [` + languagesNode + `, ` + languagesNode + `]}}`}, 200, nil)
+	ref.input <- &Repository{ID: "R_1"}
+	ref.input <- &Repository{ID: "R_2"}
+	close(ref.input)
+
+	err := ref.enricher.Handle(context.Background())
+	sent := ref.sentRequest()
+
+	ref.So(err, should.BeNil)
+	ref.So(ref.fakeClient.callNr, should.Equal, 1)
+	ref.So(sent.Query, should.ContainSubstring, "nodes(ids: $ids) {\n    ... on Repository {\n      languages(first: 100")
+	ref.So(sent.Variables, should.Resemble, map[string]interface{}{"ids": []interface{}{"R_1", "R_2"}})
+	ref.So((<-ref.output).Languages, should.HaveLength, 4)
+	ref.So((<-ref.output).Languages, should.HaveLength, 4)
+}
+
+func (ref *RepositoryEnricherFixture) TestEnricherVariablesSent() {
+	ref.enricher = NewRepositoryEnricher(ref.input, ref.output, ref.fakeClient, []Enricher{NewCommitsEnricher(time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC))})
+	ref.fakeClient.Configure([]string{`{"data": {"nodes": [` + commitsNode + `]}}`}, 200, nil)
+	ref.input <- &Repository{ID: "R_1"}
+	close(ref.input)
+
+	err := ref.enricher.Handle(context.Background())
+	sent := ref.sentRequest()
+
+	ref.So(err, should.BeNil)
+	ref.So(sent.Query, should.StartWith, "query EnrichRepositories($ids: [ID!]!, $since30: GitTimestamp!, $since90: GitTimestamp!, $since365: GitTimestamp!) {")
+	ref.So(sent.Variables, should.Resemble, map[string]interface{}{
+		"ids":      []interface{}{"R_1"},
+		"since30":  "2021-03-02T00:00:00Z",
+		"since90":  "2021-01-01T00:00:00Z",
+		"since365": "2020-04-01T00:00:00Z",
+	})
+	ref.So((<-ref.output).CommitActivity.Commits30, should.Equal, 12)
+}
+
+func (ref *RepositoryEnricherFixture) TestBatchSizeLimitsTheQueriedIDs() {
+	ref.fakeClient.Configure([]string{
+		`{"data": {"nodes": [` + languagesNode + `, ` + languagesNode + `]}}`,
+		`{"data": {"nodes": [` + languagesNode + `]}}`,
+	}, 200, nil)
+	ref.enricher.SetBatchSize(2)
+	ref.input <- &Repository{ID: "R_1"}
+	ref.input <- &Repository{ID: "R_2"}
+	ref.input <- &Repository{ID: "R_3"}
+	close(ref.input)
+
+	err := ref.enricher.Handle(context.Background())
+
+	ref.So(err, should.BeNil)
+	ref.So(ref.fakeClient.callNr, should.Equal, 2)
+	ref.So(ref.sentRequest().Variables, should.Resemble, map[string]interface{}{"ids": []interface{}{"R_3"}})
+	ref.So(ref.received(), should.Resemble, []string{"R_1", "R_2", "R_3"})
+}
+
+func (ref *RepositoryEnricherFixture) TestBatchWaitsForMoreRepositories() {
+	ref.fakeClient.Configure([]string{`{"data": {"nodes": [` + languagesNode + `, ` + languagesNode + `]}}`}, 200, nil)
+	ref.enricher.wait = time.Minute
+	ref.input <- &Repository{ID: "R_1"}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		ref.input <- &Repository{ID: "R_2"}
+		close(ref.input)
+	}()
+
+	err := ref.enricher.Handle(context.Background())
+
+	ref.So(err, should.BeNil)
+	ref.So(ref.fakeClient.callNr, should.Equal, 1)
+	ref.So(ref.sentRequest().Variables, should.Resemble, map[string]interface{}{"ids": []interface{}{"R_1", "R_2"}})
+}
+
+func (ref *RepositoryEnricherFixture) TestPartialBatchEnrichedAfterWaiting() {
+	ref.fakeClient.Configure([]string{
+		`{"data": {"nodes": [` + languagesNode + `]}}`,
+		`{"data": {"nodes": [` + languagesNode + `]}}`,
+	}, 200, nil)
+	ref.enricher.wait = time.Millisecond
+	ref.input <- &Repository{ID: "R_1"}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		ref.input <- &Repository{ID: "R_2"}
+		close(ref.input)
+	}()
+
+	ref.enricher.Handle(context.Background())
+
+	ref.So(ref.fakeClient.callNr, should.Equal, 2)
+	ref.So(ref.received(), should.Resemble, []string{"R_1", "R_2"})
+}
+
+func (ref *RepositoryEnricherFixture) TestBatchSizeCapped() {
+	ref.enricher.SetBatchSize(MaxBatchSize + 1)
+	ref.So(ref.enricher.batchSize, should.Equal, MaxBatchSize)
+
+	ref.enricher.SetBatchSize(0)
+	ref.So(ref.enricher.batchSize, should.Equal, MaxBatchSize)
+}
+
+func (ref *RepositoryEnricherFixture) TestRepositoryNotFoundSentWithoutEnrichment() {
+	ref.fakeClient.Configure([]string{`{"data": {"nodes": [null, ` + languagesNode + `]}, "errors": [{"type": "NOT_FOUND", "message": "Could not resolve to a node"}]}`}, 200, nil)
+	ref.input <- &Repository{ID: "R_1"}
+	ref.input <- &Repository{ID: "R_2"}
+	close(ref.input)
+
+	err := ref.enricher.Handle(context.Background())
+
+	ref.So(err, should.BeNil)
+	ref.So((<-ref.output).Languages, should.BeNil)
+	ref.So((<-ref.output).Languages, should.HaveLength, 4)
+}
+
+func (ref *RepositoryEnricherFixture) TestRepositoryWithoutIDNotQueried() {
+	ref.input <- &Repository{Name: "repo"}
+	close(ref.input)
+
+	err := ref.enricher.Handle(context.Background())
+
+	ref.So(err, should.BeNil)
+	ref.So((<-ref.output).Name, should.Equal, "repo")
+	ref.So(ref.fakeClient.callNr, should.Equal, 0)
+}
+
+func (ref *RepositoryEnricherFixture) TestRepositoryWithoutIDKeepsItsPlace() {
+	ref.fakeClient.Configure([]string{`{"data": {"nodes": [` + languagesNode + `]}}`}, 200, nil)
+	ref.input <- &Repository{ID: "R_1"}
+	ref.input <- &Repository{Name: "repo"}
+	close(ref.input)
+
+	ref.enricher.Handle(context.Background())
+
+	ref.So((<-ref.output).Languages, should.HaveLength, 4)
+	ref.So((<-ref.output).Languages, should.BeNil)
+	ref.So(ref.sentRequest().Variables, should.Resemble, map[string]interface{}{"ids": []interface{}{"R_1"}})
+}
+
+func (ref *RepositoryEnricherFixture) TestApiError() {
+	ref.fakeClient.Configure([]string{`{"errors": [{"type": "MAX_NODE_LIMIT_EXCEEDED", "message": "Too many nodes."}]}`}, 200, nil)
+	ref.input <- &Repository{ID: "R_1"}
+	close(ref.input)
+
+	err := ref.enricher.Handle(context.Background())
+
+	ref.So(errors.Is(err, ErrRead), should.BeTrue)
+	ref.So(err.Error(), should.Equal, "MAX_NODE_LIMIT_EXCEEDED - Too many nodes.: api error: read error")
+	ref.So(ref.received(), should.Resemble, []string{"R_1"})
+}
+
+func (ref *RepositoryEnricherFixture) TestReadErrorSendsTheRestWithoutEnrichment() {
+	ref.fakeClient.Configure(responseWithMessage, 401, nil)
+	ref.enricher.SetBatchSize(1)
+	ref.input <- &Repository{ID: "R_1"}
+	ref.input <- &Repository{ID: "R_2"}
+	close(ref.input)
+
+	err := ref.enricher.Handle(context.Background())
+
+	ref.So(errors.Is(err, ErrUnauthorized), should.BeTrue)
+	ref.So(ref.fakeClient.callNr, should.Equal, 1)
+	ref.So(ref.received(), should.Resemble, []string{"R_1", "R_2"})
+}
+
+func (ref *RepositoryEnricherFixture) TestReadErrorCancelsTheReader() {
+	ref.fakeClient.Configure(responseWithMessage, 401, nil)
+	ref.enricher.SetBatchSize(1)
+	ref.enricher.SetCancel(func() {
+		ref.input <- &Repository{ID: "R_2"}
+		close(ref.input)
+	})
+	ref.input <- &Repository{ID: "R_1"}
+
+	err := ref.enricher.Handle(context.Background())
+
+	ref.So(errors.Is(err, ErrUnauthorized), should.BeTrue)
+	ref.So(ref.received(), should.Resemble, []string{"R_1", "R_2"})
+}
+
+func (ref *RepositoryEnricherFixture) TestCancelled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	close(ref.input)
+
+	err := ref.enricher.Handle(ctx)
+	_, open := <-ref.output
+
+	ref.So(errors.Is(err, context.Canceled), should.BeTrue)
+	ref.So(open, should.BeFalse)
+}
+
+func (ref *RepositoryEnricherFixture) TestCancelledMidStreamSendsEveryRepository() {
+	ctx, cancel := context.WithCancel(context.Background())
+	ref.fakeClient.Configure([]string{
+		`{"data": {"nodes": [` + languagesNode + `, ` + languagesNode + `]}}`,
+		`{"data": {"nodes": [` + languagesNode + `, ` + languagesNode + `]}}`,
+	}, 200, nil)
+	ref.fakeClient.onRequest = cancel
+	ref.enricher.SetBatchSize(2)
+	for _, id := range []string{"R_1", "R_2", "R_3", "R_4", "R_5"} {
+		ref.input <- &Repository{ID: id}
+	}
+	close(ref.input)
+
+	err := ref.enricher.Handle(ctx)
+	var enriched int
+	var ids []string
+	for repository := range ref.output {
+		ids = append(ids, repository.ID)
+		if nil != repository.Languages {
+			enriched++
+		}
+	}
+
+	ref.So(errors.Is(err, context.Canceled), should.BeTrue)
+	ref.So(ids, should.Resemble, []string{"R_1", "R_2", "R_3", "R_4", "R_5"})
+	ref.So(enriched, should.Equal, 0)
+	ref.So(ref.fakeClient.callNr, should.Equal, 1)
+}
+
+func (ref *RepositoryEnricherFixture) sentRequest() *GraphQLRequest {
+	sent := &GraphQLRequest{}
+	body, _ := ioutil.ReadAll(ref.fakeClient.request.Body)
+	json.Unmarshal(body, sent)
+
+	return sent
+}
+
+func (ref *RepositoryEnricherFixture) received() []string {
+	var ids []string
+	for repository := range ref.output {
+		ids = append(ids, repository.ID)
+	}
+
+	return ids
+}
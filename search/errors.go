@@ -0,0 +1,120 @@
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrRead         = errors.New("read error")
+	ErrUnauthorized = fmt.Errorf("unauthorized: %w", ErrRead)
+	ErrForbidden    = fmt.Errorf("forbidden: %w", ErrRead)
+	ErrRateLimited  = fmt.Errorf("rate limited: %w", ErrRead)
+	ErrNotFound     = fmt.Errorf("not found: %w", ErrRead)
+	ErrServer       = fmt.Errorf("server error: %w", ErrRead)
+)
+
+// RateLimitError is returned when github refused a request because the rate limit
+// was exceeded. Reset is zero when github did not tell when the limit resets.
+type RateLimitError struct {
+	Message string
+	Reset   time.Time
+}
+
+func (rl *RateLimitError) Error() string {
+	if rl.Reset.IsZero() {
+		return fmt.Sprintf("%s: %s", rl.Message, ErrRateLimited.Error())
+	}
+
+	return fmt.Sprintf("%s (resets at %s): %s", rl.Message, rl.Reset.Format(time.RFC3339), ErrRateLimited.Error())
+}
+
+func (rl *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// statusError classifies a non successful response. The message github sends in
+// a JSON body is kept, any other body (e.g. an HTML error page) is replaced by the
+// HTTP status.
+func statusError(response *http.Response) error {
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return nil
+	}
+
+	message := errorMessage(response)
+
+	switch {
+	case response.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("%s: %w", message, ErrUnauthorized)
+	case isRateLimited(response, message):
+		return &RateLimitError{Message: message, Reset: rateLimitReset(response)}
+	case response.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%s: %w", message, ErrForbidden)
+	case response.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%s: %w", message, ErrNotFound)
+	case response.StatusCode >= 500:
+		return fmt.Errorf("%s: %w", message, ErrServer)
+	}
+
+	return fmt.Errorf("%s: %w", message, ErrRead)
+}
+
+func errorMessage(response *http.Response) string {
+	result := &Response{}
+	if nil != response.Body {
+		content, _ := ioutil.ReadAll(io.LimitReader(response.Body, 1024*1024))
+		json.Unmarshal(content, result)
+	}
+
+	if result.Message != "" {
+		return result.Message
+	}
+
+	if response.Status != "" {
+		return response.Status
+	}
+
+	return fmt.Sprintf("%d %s", response.StatusCode, http.StatusText(response.StatusCode))
+}
+
+func isRateLimited(response *http.Response, message string) bool {
+	if response.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	if response.StatusCode != http.StatusForbidden {
+		return false
+	}
+
+	return response.Header.Get("X-RateLimit-Remaining") == "0" || response.Header.Get("Retry-After") != "" ||
+		strings.Contains(strings.ToLower(message), "rate limit")
+}
+
+// graphQLRateLimitReset returns the reset time of the response headers, or else the
+// one of the rateLimit object in the body.
+func graphQLRateLimitReset(response *http.Response, rateLimit RateLimit) time.Time {
+	if reset := rateLimitReset(response); !reset.IsZero() {
+		return reset
+	}
+
+	return rateLimit.ResetAt.UTC()
+}
+
+func rateLimitReset(response *http.Response) time.Time {
+	if reset, err := strconv.ParseInt(response.Header.Get("X-RateLimit-Reset"), 10, 64); nil == err {
+		return time.Unix(reset, 0).UTC()
+	}
+
+	if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); nil == err {
+		return time.Now().Add(time.Duration(seconds) * time.Second).UTC()
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,169 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrField = errors.New("invalid field")
+
+// Field is a repository field that can be fetched and written. Name is the column
+// name, selection the GraphQL selection reading it and value its text in a row.
+// Fields github may return as null have a null check, value is only called for
+// the repositories where it is false.
+type Field struct {
+	Name      string
+	selection string
+	value     func(repository *Repository) string
+	null      func(repository *Repository) bool
+}
+
+var fields = []*Field{
+	{"Name", "name", func(r *Repository) string { return r.Name }, nil},
+	{"NameWithOwner", "nameWithOwner", func(r *Repository) string { return r.NameWithOwner }, nil},
+	{"Owner", "owner { login }", func(r *Repository) string { return r.Owner.Login }, nil},
+	{"Description", "description", func(r *Repository) string { return r.Description }, nil},
+	{"URL", "url", func(r *Repository) string { return r.URL }, nil},
+	{"ForkCount", "forkCount", func(r *Repository) string { return fmt.Sprintf("%d", r.ForkCount) }, nil},
+	{"Stargazers", "stargazers { totalCount }", func(r *Repository) string { return fmt.Sprintf("%d", r.Stargazers.TotalCount) }, nil},
+	{"Watchers", "watchers { totalCount }", func(r *Repository) string { return fmt.Sprintf("%d", r.Watchers.TotalCount) }, nil},
+	{"HomepageURL", "homepageUrl", func(r *Repository) string { return r.HomepageURL }, nil},
+	{"LicenseInfo", "licenseInfo { name }", func(r *Repository) string { return r.LicenseInfo.Name }, noLicense},
+	{"MentionableUsers", "mentionableUsers { totalCount }", func(r *Repository) string { return fmt.Sprintf("%d", r.MentionableUsers.TotalCount) }, nil},
+	{"MirrorURL", "mirrorUrl", func(r *Repository) string { return r.MirrorURL }, nil},
+	{"IsMirror", "isMirror", func(r *Repository) string { return strconv.FormatBool(r.IsMirror) }, nil},
+	{"PrimaryLanguage", "primaryLanguage { name }", func(r *Repository) string { return r.PrimaryLanguage.Name }, noLanguage},
+	{"Parent", "parent { name }", func(r *Repository) string { return r.Parent.Name }, noParent},
+	{"CreatedAt", "createdAt", func(r *Repository) string { return r.CreatedAt.String() }, nil},
+	{"UpdatedAt", "updatedAt", func(r *Repository) string { return r.UpdatedAt.String() }, nil},
+	{"PushedAt", "pushedAt", func(r *Repository) string { return formatTime(r.PushedAt) }, nil},
+	{"Topics", "repositoryTopics(first: 20) { nodes { topic { name } } }", func(r *Repository) string { return strings.Join(r.Topics(), ";") }, nil},
+	{"IsArchived", "isArchived", func(r *Repository) string { return strconv.FormatBool(r.IsArchived) }, nil},
+	{"IsFork", "isFork", func(r *Repository) string { return strconv.FormatBool(r.IsFork) }, nil},
+	{"IsTemplate", "isTemplate", func(r *Repository) string { return strconv.FormatBool(r.IsTemplate) }, nil},
+	{"IsDisabled", "isDisabled", func(r *Repository) string { return strconv.FormatBool(r.IsDisabled) }, nil},
+	{"DiskUsage", "diskUsage", func(r *Repository) string { return fmt.Sprintf("%d", r.DiskUsage) }, nil},
+	{"DefaultBranch", "defaultBranchRef { name }", func(r *Repository) string { return r.DefaultBranchRef.Name }, nil},
+	{"OpenIssues", "openIssues: issues(states: OPEN) { totalCount }", func(r *Repository) string { return fmt.Sprintf("%d", r.OpenIssues.TotalCount) }, nil},
+	{"OpenPullRequests", "openPullRequests: pullRequests(states: OPEN) { totalCount }", func(r *Repository) string { return fmt.Sprintf("%d", r.OpenPullRequests.TotalCount) }, nil},
+	{"LatestReleaseTag", "latestRelease { tagName }", func(r *Repository) string { return r.LatestRelease.TagName }, nil},
+	{"LatestReleaseDate", "latestRelease { publishedAt }", func(r *Repository) string { return formatTime(r.LatestRelease.PublishedAt) }, nil},
+	{"LicenseSpdxID", "licenseInfo { spdxId }", func(r *Repository) string { return r.LicenseInfo.SpdxID }, noLicense},
+	{"OwnerType", "owner { __typename }", func(r *Repository) string { return r.Owner.Typename }, nil},
+	{"OwnerURL", "owner { url }", func(r *Repository) string { return r.Owner.URL }, nil},
+	{"OwnerVerified", "owner { __typename ... on Organization { isVerified } }", func(r *Repository) string { return strconv.FormatBool(r.Owner.IsVerified) }, notOrganization},
+	{"ParentNameWithOwner", "parent { nameWithOwner }", func(r *Repository) string { return r.Parent.NameWithOwner }, noParent},
+	{"ParentURL", "parent { url }", func(r *Repository) string { return r.Parent.URL }, noParent},
+	{"ParentStargazers", "parent { stargazers { totalCount } }", func(r *Repository) string { return fmt.Sprintf("%d", r.Parent.Stargazers.TotalCount) }, noParent},
+}
+
+func noLicense(repository *Repository) bool {
+	return nil == repository.LicenseInfo
+}
+
+func noLanguage(repository *Repository) bool {
+	return nil == repository.PrimaryLanguage
+}
+
+func noParent(repository *Repository) bool {
+	return nil == repository.Parent
+}
+
+// notOrganization makes OwnerVerified null for users, only organisations can be
+// verified.
+func notOrganization(repository *Repository) bool {
+	return repository.Owner.Typename != "Organization"
+}
+
+// formatTime formats the dates which github leaves empty, e.g. the date of a missing
+// release, as an empty value.
+func formatTime(value time.Time) string {
+	if value.IsZero() {
+		return ""
+	}
+
+	return value.String()
+}
+
+// DefaultFields returns every field of the search query, in the default column
+// order. The fields of enrichers are not included.
+func DefaultFields() []*Field {
+	return append([]*Field(nil), fields...)
+}
+
+// FieldNames returns the names of every field, followed by the fields of the
+// enrichers.
+func FieldNames(enrichers ...Enricher) []string {
+	var names []string
+	for _, field := range allFields(enrichers) {
+		names = append(names, field.Name)
+	}
+
+	return names
+}
+
+// ParseFields returns the fields named in a comma separated list, in the listed
+// order. Names are case insensitive and can name fields of the enrichers; an empty
+// list selects the default fields.
+func ParseFields(names string, enrichers ...Enricher) ([]*Field, error) {
+	if strings.TrimSpace(names) == "" {
+		return DefaultFields(), nil
+	}
+
+	var (
+		selected []*Field
+		seen     = map[*Field]bool{}
+	)
+
+	for _, name := range strings.Split(names, ",") {
+		field := findField(strings.TrimSpace(name), allFields(enrichers))
+		if nil == field {
+			return nil, fmt.Errorf("unknown field %q, expected one of %s: %w", strings.TrimSpace(name), strings.Join(FieldNames(enrichers...), ", "), ErrField)
+		}
+
+		if seen[field] {
+			return nil, fmt.Errorf("field %s selected twice: %w", field.Name, ErrField)
+		}
+
+		seen[field] = true
+		selected = append(selected, field)
+	}
+
+	return selected, nil
+}
+
+func allFields(enrichers []Enricher) []*Field {
+	all := DefaultFields()
+	for _, enricher := range enrichers {
+		all = append(all, enricher.Fields()...)
+	}
+
+	return all
+}
+
+func findField(name string, fields []*Field) *Field {
+	for _, field := range fields {
+		if strings.EqualFold(field.Name, name) {
+			return field
+		}
+	}
+
+	return nil
+}
+
+// selectionSet returns the GraphQL selection of the fields. The id is always
+// selected, the reader uses it to skip duplicates and the enrichers to find the
+// repository. Fields of enrichers have no selection in the search query.
+func selectionSet(fields []*Field) string {
+	selections := []string{"id"}
+	for _, field := range fields {
+		if field.selection != "" {
+			selections = append(selections, field.selection)
+		}
+	}
+
+	return strings.Join(selections, "\n          ")
+}
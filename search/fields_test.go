@@ -0,0 +1,93 @@
+package search
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/smartystreets/gunit"
+)
+
+func TestFieldsFixture(t *testing.T) {
+	gunit.Run(new(FieldsFixture), t)
+}
+
+type FieldsFixture struct {
+	*gunit.Fixture
+}
+
+func (ff *FieldsFixture) TestParseFieldsKeepsOrder() {
+	selected, err := ParseFields("stargazers, Name ,primarylanguage")
+
+	ff.So(err, should.BeNil)
+	ff.So(ff.names(selected), should.Resemble, []string{"Stargazers", "Name", "PrimaryLanguage"})
+}
+
+func (ff *FieldsFixture) TestParseEmptyFieldsSelectsDefault() {
+	selected, err := ParseFields(" ")
+
+	ff.So(err, should.BeNil)
+	ff.So(ff.names(selected), should.Resemble, FieldNames())
+}
+
+func (ff *FieldsFixture) TestParseUnknownField() {
+	selected, err := ParseFields("Name,Stars")
+
+	ff.So(selected, should.BeNil)
+	ff.So(errors.Is(err, ErrField), should.BeTrue)
+	ff.So(err.Error(), should.StartWith, `unknown field "Stars", expected one of Name, NameWithOwner, Owner,`)
+}
+
+func (ff *FieldsFixture) TestParseDuplicateField() {
+	_, err := ParseFields("Name,URL,name")
+
+	ff.So(errors.Is(err, ErrField), should.BeTrue)
+	ff.So(err.Error(), should.Equal, "field Name selected twice: invalid field")
+}
+
+func (ff *FieldsFixture) TestSelectionSetAlwaysHasID() {
+	selected, _ := ParseFields("Owner,URL")
+
+	ff.So(selectionSet(selected), should.Equal, "id\n          owner { login }\n          url")
+}
+
+func (ff *FieldsFixture) TestDefaultFieldsCanNotBeChanged() {
+	DefaultFields()[0] = nil
+
+	ff.So(DefaultFields()[0].Name, should.Equal, "Name")
+}
+
+func (ff *FieldsFixture) TestParseEnrichmentFields() {
+	languages := NewLanguagesEnricher()
+
+	selected, err := ParseFields("Name,languages", languages)
+
+	ff.So(err, should.BeNil)
+	ff.So(selected, should.Resemble, []*Field{DefaultFields()[0], languages.Fields()[0]})
+	ff.So(selectionSet(selected), should.Equal, "id\n          name")
+	ff.So(EnrichersOf(selected, []Enricher{languages}), should.Resemble, []Enricher{languages})
+}
+
+func (ff *FieldsFixture) TestEnrichmentFieldsNeedTheirEnricher() {
+	_, err := ParseFields("Name,Languages")
+
+	ff.So(errors.Is(err, ErrField), should.BeTrue)
+}
+
+func (ff *FieldsFixture) TestEnrichersOfDefaultFields() {
+	languages := NewLanguagesEnricher()
+
+	ff.So(EnrichersOf(DefaultFields(), []Enricher{languages}), should.BeEmpty)
+	ff.So(FindEnricher("languages", []Enricher{languages}), should.Equal, languages)
+	ff.So(FindEnricher("stars", []Enricher{languages}), should.BeNil)
+}
+
+func (ff *FieldsFixture) names(selected []*Field) []string {
+	var names []string
+	for _, field := range selected {
+		names = append(names, field.Name)
+	}
+
+	return names
+}
@@ -0,0 +1,89 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	finderhttp "github.com/vcsfrl/github-tool-finder/http"
+)
+
+// GraphQLRequest is the body of a request to the github GraphQL api. The values
+// used by the query travel in Variables, never inside the query text.
+type GraphQLRequest struct {
+	Query     string      `json:"query"`
+	Variables interface{} `json:"variables"`
+}
+
+// searchVariables are the variables of repoSearchQuery. A nil After reads the
+// first page.
+type searchVariables struct {
+	Query string  `json:"query"`
+	First int     `json:"first"`
+	After *string `json:"after"`
+}
+
+// sendGraphQL posts the request and returns the successful response, whose body
+// the caller closes.
+func sendGraphQL(ctx context.Context, client finderhttp.Client, graphQL *GraphQLRequest) (*http.Response, error) {
+	if err := ctx.Err(); nil != err {
+		return nil, err
+	}
+
+	body, err := json.Marshal(graphQL)
+	if nil != err {
+		return nil, fmt.Errorf("%s: %w", err.Error(), ErrRead)
+	}
+
+	request, _ := http.NewRequestWithContext(ctx, "POST", "", bytes.NewReader(body))
+	response, err := client.Do(request)
+
+	if nil != err {
+		if nil != ctx.Err() {
+			return nil, ctx.Err()
+		}
+
+		return nil, fmt.Errorf("%s: %w", err.Error(), ErrRead)
+	}
+
+	if nil != ctx.Err() {
+		response.Body.Close()
+		return nil, ctx.Err()
+	}
+
+	if err := statusError(response); nil != err {
+		response.Body.Close()
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// responseError returns the error reported in the body of a response, nil when
+// there is none. A RATE_LIMITED error is returned as a *RateLimitError with the
+// given reset time.
+func responseError(message string, errors []GraphQLError, reset time.Time) error {
+	if message != "" {
+		return fmt.Errorf("%s: %w", message, ErrRead)
+	}
+
+	if 0 == len(errors) {
+		return nil
+	}
+
+	for _, resultErr := range errors {
+		if resultErr.Type == "RATE_LIMITED" {
+			return &RateLimitError{Message: resultErr.Message, Reset: reset}
+		}
+	}
+
+	err := fmt.Errorf("api error: %w", ErrRead)
+	for _, resultErr := range errors {
+		err = fmt.Errorf("%s - %s: %w", resultErr.Type, resultErr.Message, err)
+	}
+
+	return err
+}
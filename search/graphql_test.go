@@ -0,0 +1,81 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/smartystreets/gunit"
+)
+
+func TestGraphQLFixture(t *testing.T) {
+	gunit.Run(new(GraphQLFixture), t)
+}
+
+type GraphQLFixture struct {
+	*gunit.Fixture
+
+	fakeClient *FakeHTTPClient
+}
+
+func (gf *GraphQLFixture) Setup() {
+	gf.fakeClient = &FakeHTTPClient{}
+	gf.fakeClient.Configure(responseBody, 200, nil)
+}
+
+func (gf *GraphQLFixture) TestVariablesAreEncoded() {
+	query := `"web framework" in:description path\to "quoted \"twice\""`
+	cursor := `Y3Vy"c29y\`
+
+	response, err := sendGraphQL(context.Background(), gf.fakeClient, &GraphQLRequest{
+		Query:     searchQuery(DefaultFields()),
+		Variables: &searchVariables{Query: query, First: 10, After: &cursor},
+	})
+
+	sent := &searchVariables{}
+	body, _ := ioutil.ReadAll(gf.fakeClient.request.Body)
+	decodeErr := json.Unmarshal(body, &GraphQLRequest{Variables: sent})
+
+	gf.So(err, should.BeNil)
+	gf.So(response.Body, should.Equal, gf.fakeClient.responseBody)
+	gf.So(decodeErr, should.BeNil)
+	gf.So(sent.Query, should.Equal, query)
+	gf.So(sent.First, should.Equal, 10)
+	gf.So(*sent.After, should.Equal, cursor)
+}
+
+func (gf *GraphQLFixture) TestQueryTextIsNotTemplated() {
+	sendGraphQL(context.Background(), gf.fakeClient, &GraphQLRequest{
+		Query:     searchQuery(DefaultFields()),
+		Variables: &searchVariables{Query: `"%s" \n`, First: 1},
+	})
+
+	sent := &GraphQLRequest{}
+	body, _ := ioutil.ReadAll(gf.fakeClient.request.Body)
+	json.Unmarshal(body, sent)
+
+	gf.So(sent.Query, should.Equal, searchQuery(DefaultFields()))
+	gf.So(sent.Variables, should.Resemble, map[string]interface{}{"query": `"%s" \n`, "first": 1.0, "after": nil})
+}
+
+func (gf *GraphQLFixture) TestErrorResponseClosed() {
+	gf.fakeClient.Configure(responseWithMessage, 401, nil)
+
+	response, err := sendGraphQL(context.Background(), gf.fakeClient, &GraphQLRequest{Query: searchQuery(DefaultFields())})
+
+	gf.So(response, should.BeNil)
+	gf.So(errors.Is(err, ErrUnauthorized), should.BeTrue)
+	gf.So(gf.fakeClient.responseBody.closed, should.Equal, 1)
+}
+
+func (gf *GraphQLFixture) TestUnencodableVariables() {
+	response, err := sendGraphQL(context.Background(), gf.fakeClient, &GraphQLRequest{Variables: make(chan int)})
+
+	gf.So(response, should.BeNil)
+	gf.So(errors.Is(err, ErrRead), should.BeTrue)
+	gf.So(gf.fakeClient.callNr, should.Equal, 0)
+}
@@ -0,0 +1,165 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// CommunityHealth tells which community files and settings a repository has.
+type CommunityHealth struct {
+	Readme         bool
+	Contributing   bool
+	CodeOfConduct  bool
+	SecurityPolicy bool
+	IssueTemplates bool
+	CodeOwners     bool
+	Workflows      bool
+}
+
+// Percent returns the share of the checks the repository passes.
+func (ch *CommunityHealth) Percent() int {
+	checks := []bool{ch.Readme, ch.Contributing, ch.CodeOfConduct, ch.SecurityPolicy, ch.IssueTemplates, ch.CodeOwners, ch.Workflows}
+
+	passed := 0
+	for _, check := range checks {
+		if check {
+			passed++
+		}
+	}
+
+	return int(math.Round(float64(passed) * 100 / float64(len(checks))))
+}
+
+func NewHealthEnricher() *HealthEnricher {
+	he := &HealthEnricher{}
+	he.fields = []*Field{
+		{"HasReadme", "", healthCheck(func(h *CommunityHealth) bool { return h.Readme }), noCommunityHealth},
+		{"HasContributing", "", healthCheck(func(h *CommunityHealth) bool { return h.Contributing }), noCommunityHealth},
+		{"HasCodeOfConduct", "", healthCheck(func(h *CommunityHealth) bool { return h.CodeOfConduct }), noCommunityHealth},
+		{"HasSecurityPolicy", "", healthCheck(func(h *CommunityHealth) bool { return h.SecurityPolicy }), noCommunityHealth},
+		{"HasIssueTemplates", "", healthCheck(func(h *CommunityHealth) bool { return h.IssueTemplates }), noCommunityHealth},
+		{"HasCodeOwners", "", healthCheck(func(h *CommunityHealth) bool { return h.CodeOwners }), noCommunityHealth},
+		{"HasWorkflows", "", healthCheck(func(h *CommunityHealth) bool { return h.Workflows }), noCommunityHealth},
+		{"HealthPercent", "", func(r *Repository) string { return strconv.Itoa(r.CommunityHealth.Percent()) }, noCommunityHealth},
+	}
+
+	return he
+}
+
+// HealthEnricher checks the community files of the default branch, looked up in
+// the root, .github and docs directories like github does, and the security
+// policy and code of conduct github detects.
+type HealthEnricher struct {
+	fields []*Field
+}
+
+func (he *HealthEnricher) Name() string {
+	return "health"
+}
+
+func (he *HealthEnricher) Fields() []*Field {
+	return he.fields
+}
+
+func (he *HealthEnricher) Selection() string {
+	return `healthRoot: object(expression: "HEAD:") { ... on Tree { entries { name } } } ` +
+		`healthGithub: object(expression: "HEAD:.github") { ... on Tree { entries { name } } } ` +
+		`healthDocs: object(expression: "HEAD:docs") { ... on Tree { entries { name } } } ` +
+		`healthWorkflows: object(expression: "HEAD:.github/workflows") { ... on Tree { entries { name } } } ` +
+		`issueTemplates { name } isSecurityPolicyEnabled securityPolicyUrl codeOfConduct { key }`
+}
+
+func (he *HealthEnricher) Enrich(repository *Repository, node json.RawMessage) error {
+	result := &struct {
+		HealthRoot      *tree `json:"healthRoot"`
+		HealthGithub    *tree `json:"healthGithub"`
+		HealthDocs      *tree `json:"healthDocs"`
+		HealthWorkflows *tree `json:"healthWorkflows"`
+		IssueTemplates  []struct {
+			Name string `json:"name"`
+		} `json:"issueTemplates"`
+		IsSecurityPolicyEnabled bool    `json:"isSecurityPolicyEnabled"`
+		SecurityPolicyURL       *string `json:"securityPolicyUrl"`
+		CodeOfConduct           *struct {
+			Key string `json:"key"`
+		} `json:"codeOfConduct"`
+	}{}
+
+	if err := json.Unmarshal(node, result); nil != err {
+		return fmt.Errorf("community health of %s, %s: %w", repository.NameWithOwner, err.Error(), ErrRead)
+	}
+
+	// The community files are read from the root, .github or docs directory.
+	community := []*tree{result.HealthRoot, result.HealthGithub, result.HealthDocs}
+	hasFile := func(name string) bool {
+		for _, directory := range community {
+			if directory.has(name) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	repository.CommunityHealth = &CommunityHealth{
+		Readme:         hasFile("README"),
+		Contributing:   hasFile("CONTRIBUTING"),
+		CodeOfConduct:  nil != result.CodeOfConduct || hasFile("CODE_OF_CONDUCT"),
+		SecurityPolicy: result.IsSecurityPolicyEnabled || nil != result.SecurityPolicyURL || hasFile("SECURITY"),
+		IssueTemplates: len(result.IssueTemplates) > 0 || hasFile("ISSUE_TEMPLATE"),
+		CodeOwners:     hasFile("CODEOWNERS"),
+		Workflows:      result.HealthWorkflows.hasWorkflow(),
+	}
+
+	return nil
+}
+
+// tree is a directory of the default branch, nil when it does not exist.
+type tree struct {
+	Entries []struct {
+		Name string `json:"name"`
+	} `json:"entries"`
+}
+
+// has tells whether the directory has an entry with the name, in any case and
+// with any extension, e.g. readme.md or README.rst for README.
+func (t *tree) has(name string) bool {
+	if nil == t {
+		return false
+	}
+
+	for _, entry := range t.Entries {
+		if strings.EqualFold(entry.Name, name) || strings.HasPrefix(strings.ToUpper(entry.Name), strings.ToUpper(name)+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *tree) hasWorkflow() bool {
+	if nil == t {
+		return false
+	}
+
+	for _, entry := range t.Entries {
+		if name := strings.ToLower(entry.Name); strings.HasSuffix(name, ".yml") || strings.HasSuffix(name, ".yaml") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func healthCheck(check func(*CommunityHealth) bool) func(*Repository) string {
+	return func(repository *Repository) string {
+		return strconv.FormatBool(check(repository.CommunityHealth))
+	}
+}
+
+func noCommunityHealth(repository *Repository) bool {
+	return nil == repository.CommunityHealth
+}
@@ -0,0 +1,93 @@
+package search
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/smartystreets/gunit"
+)
+
+func TestHealthEnricherFixture(t *testing.T) {
+	gunit.Run(new(HealthEnricherFixture), t)
+}
+
+type HealthEnricherFixture struct {
+	*gunit.Fixture
+
+	enricher   *HealthEnricher
+	repository *Repository
+}
+
+func (hef *HealthEnricherFixture) Setup() {
+	hef.enricher = NewHealthEnricher()
+	hef.repository = &Repository{NameWithOwner: "owner/cli"}
+}
+
+func (hef *HealthEnricherFixture) TestSelectionLooksUpTheCommunityDirectories() {
+	selection := hef.enricher.Selection()
+
+	hef.So(selection, should.ContainSubstring, `healthRoot: object(expression: "HEAD:") { ... on Tree { entries { name } } }`)
+	hef.So(selection, should.ContainSubstring, `healthWorkflows: object(expression: "HEAD:.github/workflows")`)
+	hef.So(selection, should.EndWith, "isSecurityPolicyEnabled securityPolicyUrl codeOfConduct { key }")
+}
+
+func (hef *HealthEnricherFixture) TestFilesFoundInCommunityDirectories() {
+	err := hef.enricher.Enrich(hef.repository, []byte(healthNode))
+
+	hef.So(err, should.BeNil)
+	hef.So(hef.repository.CommunityHealth, should.Resemble, &CommunityHealth{
+		Readme:         true,
+		Contributing:   true,
+		CodeOfConduct:  false,
+		SecurityPolicy: true,
+		IssueTemplates: true,
+		CodeOwners:     true,
+		Workflows:      true,
+	})
+	hef.So(fieldValues(hef.enricher, hef.repository), should.Resemble, []string{"true", "true", "false", "true", "true", "true", "true", "86"})
+}
+
+func (hef *HealthEnricherFixture) TestDetectedPolicies() {
+	err := hef.enricher.Enrich(hef.repository, []byte(`{
+		"healthRoot": {"entries": [{"name": "readme"}]}, "healthGithub": null, "healthDocs": null, "healthWorkflows": null,
+		"issueTemplates": [{"name": "Bug report"}],
+		"isSecurityPolicyEnabled": false, "securityPolicyUrl": "https://github.com/owner/cli/security/policy",
+		"codeOfConduct": {"key": "contributor_covenant"}
+	}`))
+
+	hef.So(err, should.BeNil)
+	hef.So(fieldValues(hef.enricher, hef.repository), should.Resemble, []string{"true", "false", "true", "true", "true", "false", "false", "57"})
+}
+
+func (hef *HealthEnricherFixture) TestEmptyRepository() {
+	err := hef.enricher.Enrich(hef.repository, []byte(`{
+		"healthRoot": null, "healthGithub": null, "healthDocs": null, "healthWorkflows": null,
+		"issueTemplates": [], "isSecurityPolicyEnabled": false, "securityPolicyUrl": null, "codeOfConduct": null
+	}`))
+
+	hef.So(err, should.BeNil)
+	hef.So(hef.repository.CommunityHealth, should.Resemble, &CommunityHealth{})
+	hef.So(hef.repository.CommunityHealth.Percent(), should.Equal, 0)
+}
+
+func (hef *HealthEnricherFixture) TestFileNames() {
+	directory := &tree{}
+	json.Unmarshal([]byte(`{"entries": [{"name": "Readme.rst"}]}`), directory)
+
+	hef.So(directory.has("README"), should.BeTrue)
+	hef.So(directory.has("READ"), should.BeFalse)
+	hef.So((*tree)(nil).has("README"), should.BeFalse)
+}
+
+const healthNode = `{
+    "healthRoot": {"entries": [{"name": ".github"}, {"name": "README.md"}, {"name": "SECURITY.md"}, {"name": "main.go"}]},
+    "healthGithub": {"entries": [{"name": "CODEOWNERS"}, {"name": "ISSUE_TEMPLATE"}, {"name": "workflows"}]},
+    "healthDocs": {"entries": [{"name": "contributing.md"}]},
+    "healthWorkflows": {"entries": [{"name": "README.txt"}, {"name": "test.yaml"}]},
+    "issueTemplates": [],
+    "isSecurityPolicyEnabled": false,
+    "securityPolicyUrl": null,
+    "codeOfConduct": null
+}`
@@ -0,0 +1,87 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// LanguageShare is the part of the code of a repository written in a language.
+type LanguageShare struct {
+	Name    string
+	Bytes   int64
+	Percent float64
+}
+
+func NewLanguagesEnricher() *LanguagesEnricher {
+	le := &LanguagesEnricher{}
+	le.fields = []*Field{
+		{"Languages", "", formatLanguages, func(r *Repository) bool { return nil == r.Languages }},
+	}
+
+	return le
+}
+
+// LanguagesEnricher fetches the languages of a repository with their size, the
+// largest first.
+type LanguagesEnricher struct {
+	fields []*Field
+}
+
+func (le *LanguagesEnricher) Name() string {
+	return "languages"
+}
+
+func (le *LanguagesEnricher) Fields() []*Field {
+	return le.fields
+}
+
+func (le *LanguagesEnricher) Selection() string {
+	return "languages(first: 100, orderBy: {field: SIZE, direction: DESC}) { totalSize edges { size node { name } } }"
+}
+
+func (le *LanguagesEnricher) Enrich(repository *Repository, node json.RawMessage) error {
+	result := &struct {
+		Languages struct {
+			TotalSize int64 `json:"totalSize"`
+			Edges     []struct {
+				Size int64 `json:"size"`
+				Node struct {
+					Name string `json:"name"`
+				} `json:"node"`
+			} `json:"edges"`
+		} `json:"languages"`
+	}{}
+
+	if err := json.Unmarshal(node, result); nil != err {
+		return fmt.Errorf("languages of %s, %s: %w", repository.NameWithOwner, err.Error(), ErrRead)
+	}
+
+	repository.Languages = []LanguageShare{}
+
+	for _, edge := range result.Languages.Edges {
+		share := LanguageShare{Name: edge.Node.Name, Bytes: edge.Size}
+		if result.Languages.TotalSize > 0 {
+			share.Percent = float64(edge.Size) * 100 / float64(result.Languages.TotalSize)
+		}
+
+		repository.Languages = append(repository.Languages, share)
+	}
+
+	return nil
+}
+
+// formatLanguages writes the languages as "Go:72%;TypeScript:25%", leaving out the
+// languages under 1%.
+func formatLanguages(repository *Repository) string {
+	var shares []string
+
+	for _, language := range repository.Languages {
+		if percent := math.Round(language.Percent); percent >= 1 {
+			shares = append(shares, fmt.Sprintf("%s:%.0f%%", language.Name, percent))
+		}
+	}
+
+	return strings.Join(shares, ";")
+}
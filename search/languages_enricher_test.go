@@ -0,0 +1,66 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/smartystreets/gunit"
+)
+
+func TestLanguagesEnricherFixture(t *testing.T) {
+	gunit.Run(new(LanguagesEnricherFixture), t)
+}
+
+type LanguagesEnricherFixture struct {
+	*gunit.Fixture
+
+	enricher   *LanguagesEnricher
+	repository *Repository
+}
+
+func (lef *LanguagesEnricherFixture) Setup() {
+	lef.enricher = NewLanguagesEnricher()
+	lef.repository = &Repository{NameWithOwner: "owner/cli"}
+}
+
+func (lef *LanguagesEnricherFixture) TestPercentagesComputed() {
+	err := lef.enricher.Enrich(lef.repository, []byte(languagesNode))
+
+	lef.So(err, should.BeNil)
+	lef.So(lef.repository.Languages, should.Resemble, []LanguageShare{
+		{Name: "Go", Bytes: 7200, Percent: 72},
+		{Name: "TypeScript", Bytes: 2500, Percent: 25},
+		{Name: "Shell", Bytes: 260, Percent: 2.6},
+		{Name: "Makefile", Bytes: 40, Percent: 0.4},
+	})
+}
+
+func (lef *LanguagesEnricherFixture) TestColumnLeavesOutSmallLanguages() {
+	lef.enricher.Enrich(lef.repository, []byte(languagesNode))
+
+	lef.So(lef.enricher.Fields()[0].Name, should.Equal, "Languages")
+	lef.So(formatLanguages(lef.repository), should.Equal, "Go:72%;TypeScript:25%;Shell:3%")
+}
+
+func (lef *LanguagesEnricherFixture) TestRepositoryWithoutCode() {
+	err := lef.enricher.Enrich(lef.repository, []byte(`{"languages": {"totalSize": 0, "edges": []}}`))
+
+	lef.So(err, should.BeNil)
+	lef.So(lef.repository.Languages, should.NotBeNil)
+	lef.So(lef.repository.Languages, should.BeEmpty)
+	lef.So(lef.enricher.Fields()[0].null(lef.repository), should.BeFalse)
+	lef.So(formatLanguages(lef.repository), should.Equal, "")
+}
+
+const languagesNode = `{
+    "languages": {
+        "totalSize": 10000,
+        "edges": [
+            {"size": 7200, "node": {"name": "Go"}},
+            {"size": 2500, "node": {"name": "TypeScript"}},
+            {"size": 260, "node": {"name": "Shell"}},
+            {"size": 40, "node": {"name": "Makefile"}}
+        ]
+    }
+}`
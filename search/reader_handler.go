@@ -1,17 +1,16 @@
 package search
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"strings"
 
 	finderhttp "github.com/vcsfrl/github-tool-finder/http"
 )
 
-var ErrRead = errors.New("read error")
+// errTotalReached stops the slicing once enough repositories were read.
+var errTotalReached = errors.New("total reached")
 
 type RepositoryReader struct {
 	query    string
@@ -19,6 +18,28 @@ type RepositoryReader struct {
 	pageSize int
 	client   finderhttp.Client
 	output   chan *Repository
+	graphQL  string
+	slicer   Slicer
+	cursor   string
+	sent     int
+	seen     map[string]bool
+}
+
+// SelectFields fetches only the given repository fields, the other fields of the
+// read repositories are left empty.
+func (sr *RepositoryReader) SelectFields(fields []*Field) {
+	sr.graphQL = searchQuery(fields)
+}
+
+// UseSlicer splits queries exceeding the search result cap with the slicer.
+func (sr *RepositoryReader) UseSlicer(slicer Slicer) {
+	sr.slicer = slicer
+}
+
+// Cursor returns the cursor of the last repository sent to the output, the position
+// where a stopped read ended.
+func (sr *RepositoryReader) Cursor() string {
+	return sr.cursor
 }
 
 func (sr *RepositoryReader) Close() error {
@@ -27,179 +48,180 @@ func (sr *RepositoryReader) Close() error {
 	return nil
 }
 
-func (sr *RepositoryReader) Handle() error {
+// Handle reads the repositories and sends them to the output channel, which is
+// closed when done. Cancelling the context stops the read.
+func (sr *RepositoryReader) Handle(ctx context.Context) error {
 	defer sr.Close()
 	sr.adjustPageSize()
 
-	return sr.paginatedRead()
+	if nil == sr.slicer {
+		return sr.paginatedRead(ctx, sr.query)
+	}
+
+	err := sr.slicer.Slice(ctx, sr.query, sr, func(query string) error {
+		return sr.paginatedRead(ctx, query)
+	})
+
+	if errors.Is(err, errTotalReached) {
+		return nil
+	}
+
+	return err
+}
+
+// Count returns the number of repositories matching the query.
+func (sr *RepositoryReader) Count(ctx context.Context, query string) (int, error) {
+	result, err := sr.readRepositories(ctx, query, 1, "")
+	if nil != err {
+		return 0, err
+	}
+
+	return result.Data.Search.RepositoryCount, nil
 }
 
 func (sr *RepositoryReader) adjustPageSize() {
-	if sr.pageSize > sr.total {
+	if sr.total > 0 && sr.pageSize > sr.total {
 		sr.pageSize = sr.total
 	}
 }
 
-func (sr *RepositoryReader) paginatedRead() error {
-	var (
-		result *Response
-		cursor string
-	)
+// paginatedRead reads the query page by page until the total is reached or the
+// query has no next page.
+func (sr *RepositoryReader) paginatedRead(ctx context.Context, query string) error {
+	var cursor string
 
-	for i := 0; i < sr.total; i += sr.pageSize {
-		result = sr.readRepositories(sr.calculateLimit(i), sr.findCursor(result, cursor))
-		if err := sr.sendResult(result); nil != err {
+	for !sr.totalReached() {
+		result, err := sr.readRepositories(ctx, query, sr.calculateLimit(), cursor)
+		if nil != err {
 			return err
 		}
+
+		if err := sr.sendResult(ctx, result); nil != err {
+			return err
+		}
+
+		page := result.Data.Search.PageInfo
+		if !page.HasNextPage || page.EndCursor == "" || len(result.Data.Search.Edges) == 0 {
+			return nil
+		}
+
+		cursor = page.EndCursor
 	}
 
-	return nil
+	return errTotalReached
 }
 
-func (sr *RepositoryReader) calculateLimit(readIndex int) int {
+// totalReached tells whether enough repositories were sent. A total of 0 or less
+// reads every repository of the query.
+func (sr *RepositoryReader) totalReached() bool {
+	return sr.total > 0 && sr.sent >= sr.total
+}
+
+func (sr *RepositoryReader) calculateLimit() int {
 	limit := sr.pageSize
-	if readIndex+sr.pageSize > sr.total {
-		limit = sr.total - readIndex
+	if sr.total > 0 && sr.sent+sr.pageSize > sr.total {
+		limit = sr.total - sr.sent
 	}
 
 	return limit
 }
 
-func (sr *RepositoryReader) findCursor(previousResult *Response, cursor string) string {
-	if nil != previousResult {
-		cursor = previousResult.Data.Search.Edges[len(previousResult.Data.Search.Edges)-1].Cursor
+func (sr *RepositoryReader) readRepositories(ctx context.Context, query string, limit int, cursor string) (*Response, error) {
+	response, err := sendGraphQL(ctx, sr.client, sr.buildQl(query, limit, cursor))
+	if nil != err {
+		return nil, err
 	}
+	defer response.Body.Close()
 
-	return cursor
-}
-
-func (sr *RepositoryReader) readRepositories(limit int, cursor string) *Response {
 	result := &Response{}
-	reader, err := sr.repositoryQueryReader(sr.buildQl(limit, cursor))
-
-	if nil != reader {
-		defer reader.Close()
+	if err := json.NewDecoder(response.Body).Decode(result); nil != err {
+		return nil, fmt.Errorf("%s: %w", err.Error(), ErrRead)
 	}
 
-	sr.decodeRepositories(reader, result, err)
-
-	return result
+	return result, responseError(result.Message, result.Errors, graphQLRateLimitReset(response, result.Data.RateLimit))
 }
 
-func (sr *RepositoryReader) sendResult(result *Response) error {
-	if done, err := sr.getErrors(result); done {
-		return err
-	}
-
+func (sr *RepositoryReader) sendResult(ctx context.Context, result *Response) error {
 	for _, edge := range result.Data.Search.Edges {
 		node := edge.Node
-		sr.output <- &node
+
+		if sr.isDuplicate(&node) || sr.totalReached() {
+			continue
+		}
+
+		select {
+		case sr.output <- &node:
+			sr.cursor = edge.Cursor
+			sr.sent++
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	}
 
 	return nil
 }
 
-func (sr *RepositoryReader) decodeRepositories(reader io.Reader, result *Response, err error) {
-	if nil != err {
-		result.Message = err.Error()
-		return
+// isDuplicate tells whether the repository was already sent, which happens when
+// results move between slices or pages while reading.
+func (sr *RepositoryReader) isDuplicate(repository *Repository) bool {
+	if repository.ID == "" {
+		return false
 	}
 
-	decoder := json.NewDecoder(reader)
-	err = decoder.Decode(result)
-
-	if nil != err {
-		result.Message = err.Error()
+	if sr.seen[repository.ID] {
+		return true
 	}
-}
 
-func (sr *RepositoryReader) repositoryQueryReader(query string) (io.ReadCloser, error) {
-	request, _ := http.NewRequest("POST", "", strings.NewReader(query))
-	response, err := sr.client.Do(request)
+	sr.seen[repository.ID] = true
 
-	if nil != err {
-		return nil, err
-	}
-
-	return response.Body, nil
+	return false
 }
 
-func (sr *RepositoryReader) buildQl(limit int, cursor string) string {
+func (sr *RepositoryReader) buildQl(query string, limit int, cursor string) *GraphQLRequest {
+	variables := &searchVariables{Query: query, First: limit}
 	if cursor != "" {
-		cursor = fmt.Sprintf(", after: \\\"%s\\\"", cursor)
+		variables.After = &cursor
 	}
 
-	return fmt.Sprintf(repoSearchQuery, sr.query, limit, cursor)
+	return &GraphQLRequest{Query: sr.graphQL, Variables: variables}
 }
 
-func (sr *RepositoryReader) getErrors(result *Response) (bool, error) {
-	if result.Message != "" {
-		return true, fmt.Errorf("%s: %w", result.Message, ErrRead)
-	}
-
-	if 0 < len(result.Errors) {
-		err := sr.wrapErrors(result)
-		return true, err
+func NewRepositoryReader(query string, total int, output chan *Repository, client finderhttp.Client) *RepositoryReader {
+	return &RepositoryReader{
+		query:    query,
+		total:    total,
+		output:   output,
+		client:   client,
+		pageSize: 100,
+		graphQL:  searchQuery(DefaultFields()),
+		seen:     map[string]bool{},
 	}
-
-	return false, nil
 }
 
-func (sr *RepositoryReader) wrapErrors(result *Response) error {
-	err := fmt.Errorf("api error: %w", ErrRead)
-
-	for _, resultErr := range result.Errors {
-		err = fmt.Errorf("%s - %s: %w", resultErr.Type, resultErr.Message, err)
-	}
-
-	return err
+// searchQuery returns the search query selecting the fields of every repository.
+func searchQuery(fields []*Field) string {
+	return fmt.Sprintf(repoSearchQuery, selectionSet(fields))
 }
 
-func NewRepositoryReader(query string, total int, output chan *Repository, client finderhttp.Client) *RepositoryReader {
-	return &RepositoryReader{query: query, total: total, output: output, client: client, pageSize: 100}
-}
-
-const repoSearchQuery = "{\"query\":\"query SearchRepositories {\\n" +
-	"  search(query: \\\"%s\\\", type: REPOSITORY, first:%d%s){\\n" +
-	"    repositoryCount\\n" +
-	"    edges {\\n" +
-	"      cursor \\n" +
-	"      node {\\n" +
-	"\\t\\t\\t\\t... on Repository {\\n" +
-	"          description\\n" +
-	"          name\\n" +
-	"          nameWithOwner\\n" +
-	"          url\\n" +
-	"          owner {\\n" +
-	"            login\\n" +
-	"          }\\n" +
-	"          forkCount\\n" +
-	"          stargazers {\\n" +
-	"            totalCount\\n" +
-	"          }\\n" +
-	"          watchers {\\n" +
-	"            totalCount\\n" +
-	"          }\\n" +
-	"          homepageUrl\\n" +
-	"          licenseInfo {\\n" +
-	"            name\\n" +
-	"          }\\n" +
-	"          mentionableUsers {\\n" +
-	"            totalCount\\n" +
-	"          }\\n" +
-	"          mirrorUrl\\n" +
-	"          isMirror\\n" +
-	"          primaryLanguage {\\n" +
-	"            name\\n" +
-	"          }\\n" +
-	"          parent {\\n" +
-	"            name\\n" +
-	"          }\\n" +
-	"          createdAt\\n" +
-	"          updatedAt\\n" +
-	"        }\\n" +
-	"      }\\n" +
-	"    }\\n" +
-	"  }\\n" +
-	"}\\n" +
-	"\",\"variables\":{}}"
+const repoSearchQuery = `query SearchRepositories($query: String!, $first: Int!, $after: String) {
+  rateLimit {
+    remaining
+    resetAt
+  }
+  search(query: $query, type: REPOSITORY, first: $first, after: $after) {
+    repositoryCount
+    pageInfo {
+      hasNextPage
+      endCursor
+    }
+    edges {
+      cursor
+      node {
+        ... on Repository {
+          %s
+        }
+      }
+    }
+  }
+}
+`
@@ -2,10 +2,13 @@ package search
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -36,32 +39,47 @@ func (srf *SearchReaderFixture) Setup() {
 
 func (srf *SearchReaderFixture) TestReadResponse() {
 	srf.fakeClient.Configure(responseBody, 200, nil)
-	srf.searchReader.Handle()
+	srf.searchReader.Handle(context.Background())
 	body, _ := ioutil.ReadAll(srf.fakeClient.request.Body)
 
-	srf.So(string(body), should.Equal, grapqlQuery1Result)
+	srf.So(string(body), should.Equal, graphQLBody(`{"query":"test:test test","first":1,"after":null}`))
 	srf.So(<-srf.output, should.Resemble, getResponseRepository(1))
 	srf.So(srf.fakeClient.responseBody.closed, should.Equal, 1)
 	srf.So(srf.fakeClient.callNr, should.Equal, 1)
 }
 
+func (srf *SearchReaderFixture) TestSelectedFieldsRead() {
+	selected, _ := ParseFields("Stargazers,Name")
+	srf.searchReader.SelectFields(selected)
+	srf.fakeClient.Configure(responseBody, 200, nil)
+	srf.searchReader.Handle(context.Background())
+
+	sent := &GraphQLRequest{}
+	body, _ := ioutil.ReadAll(srf.fakeClient.request.Body)
+	json.Unmarshal(body, sent)
+
+	srf.So(sent.Query, should.ContainSubstring, "... on Repository {\n          id\n          stargazers { totalCount }\n          name\n        }")
+	srf.So(sent.Query, should.NotContainSubstring, "description")
+}
+
 func (srf *SearchReaderFixture) TestPaginatedRead() {
 	srf.searchReader.total = 2
 	srf.fakeClient.Configure(responseBody, 200, nil)
-	srf.searchReader.Handle()
+	srf.searchReader.Handle(context.Background())
 
 	srf.So(srf.fakeClient.callNr, should.Equal, 2)
 	body, _ := ioutil.ReadAll(srf.fakeClient.request.Body)
-	srf.So(string(body), should.Equal, grapqlQuery2Result)
+	srf.So(string(body), should.Equal, graphQLBody(`{"query":"test:test test","first":1,"after":"aaa"}`))
 	srf.So(<-srf.output, should.Resemble, getResponseRepository(1))
 	srf.So(<-srf.output, should.Resemble, getResponseRepository(2))
 	srf.So(srf.fakeClient.responseBody.closed, should.Equal, 1)
+	srf.So(srf.searchReader.Cursor(), should.Equal, "bbb")
 }
 
 func (srf *SearchReaderFixture) TestPaginatedReadPageSizeLargerThanTotal() {
 	srf.searchReader.pageSize = 3
 	srf.fakeClient.Configure(responseBody, 200, nil)
-	srf.searchReader.Handle()
+	srf.searchReader.Handle(context.Background())
 
 	srf.So(srf.searchReader.pageSize, should.Equal, 1)
 }
@@ -70,24 +88,159 @@ func (srf *SearchReaderFixture) TestPaginatedReadIncompleteLastPage() {
 	srf.searchReader.pageSize = 2
 	srf.searchReader.total = 3
 	srf.fakeClient.Configure(responseBody, 200, nil)
-	srf.searchReader.Handle()
+	srf.searchReader.Handle(context.Background())
 
 	body, _ := ioutil.ReadAll(srf.fakeClient.request.Body)
-	srf.So(string(body), should.Equal, grapqlQuery2Result)
+	srf.So(srf.fakeClient.callNr, should.Equal, 3)
+	srf.So(string(body), should.ContainSubstring, `"variables":{"query":"test:test test","first":1,"after":"bbb"}`)
+}
+
+func (srf *SearchReaderFixture) TestReadStopsWhenResultsExhausted() {
+	srf.searchReader.total = 5
+	srf.fakeClient.Configure([]string{responseBody[0], responseLastPage[0]}, 200, nil)
+	err := srf.searchReader.Handle(context.Background())
+
+	srf.So(err, should.BeNil)
+	srf.So(srf.fakeClient.callNr, should.Equal, 2)
+	srf.So(<-srf.output, should.Resemble, getResponseRepository(1))
+	srf.So(<-srf.output, should.Resemble, getResponseRepository(2))
+	srf.So(srf.searchReader.Cursor(), should.Equal, "bbb")
+}
+
+func (srf *SearchReaderFixture) TestReadStopsOnEmptyPage() {
+	srf.searchReader.total = 5
+	srf.fakeClient.Configure([]string{responseBody[0], responseEmptyPage[0]}, 200, nil)
+	err := srf.searchReader.Handle(context.Background())
+
+	srf.So(err, should.BeNil)
+	srf.So(srf.fakeClient.callNr, should.Equal, 2)
+	srf.So(<-srf.output, should.Resemble, getResponseRepository(1))
+	srf.So(srf.searchReader.Cursor(), should.Equal, "aaa")
+}
+
+func (srf *SearchReaderFixture) TestReadEverything() {
+	srf.searchReader = NewRepositoryReader("test:test test", 0, srf.output, srf.fakeClient)
+	srf.fakeClient.Configure([]string{responseBody[0], responseBody[1], responseLastPage[0]}, 200, nil)
+	err := srf.searchReader.Handle(context.Background())
+
+	body, _ := ioutil.ReadAll(srf.fakeClient.request.Body)
+	srf.So(err, should.BeNil)
+	srf.So(srf.fakeClient.callNr, should.Equal, 3)
+	srf.So(string(body), should.ContainSubstring, `"variables":{"query":"test:test test","first":100,"after":"bbb"}`)
+	srf.So(<-srf.output, should.Resemble, getResponseRepository(1))
+	srf.So(<-srf.output, should.Resemble, getResponseRepository(2))
+}
+
+func (srf *SearchReaderFixture) TestDuplicatesSkipped() {
+	srf.searchReader.total = 2
+	srf.fakeClient.Configure([]string{responseBody[0], responseBody[0], responseBody[1]}, 200, nil)
+	srf.searchReader.Handle(context.Background())
+
+	srf.So(srf.fakeClient.callNr, should.Equal, 3)
+	srf.So(<-srf.output, should.Resemble, getResponseRepository(1))
+	srf.So(<-srf.output, should.Resemble, getResponseRepository(2))
+}
+
+func (srf *SearchReaderFixture) TestCount() {
+	srf.fakeClient.Configure(responseBody, 200, nil)
+	count, err := srf.searchReader.Count(context.Background(), "other query")
+
+	body, _ := ioutil.ReadAll(srf.fakeClient.request.Body)
+	srf.So(err, should.BeNil)
+	srf.So(count, should.Equal, 128)
+	srf.So(string(body), should.ContainSubstring, `"variables":{"query":"other query","first":1,"after":null}`)
 }
 
 func (srf *SearchReaderFixture) TestReadError() {
 	srf.fakeClient.Configure(responseWithMessage, 401, nil)
-	err := srf.searchReader.Handle()
+	err := srf.searchReader.Handle(context.Background())
 
 	srf.So(err, should.BeError)
-	srf.So(err.Error(), should.Equal, "Bad credentials: read error")
+	srf.So(err.Error(), should.Equal, "Bad credentials: unauthorized: read error")
+	srf.So(errors.Is(err, ErrUnauthorized), should.BeTrue)
+	srf.So(errors.Is(err, ErrRead), should.BeTrue)
 	srf.So(srf.fakeClient.responseBody.closed, should.Equal, 1)
 }
 
+func (srf *SearchReaderFixture) TestServerErrorPage() {
+	srf.fakeClient.Configure(responseHTMLPage, 502, nil)
+	err := srf.searchReader.Handle(context.Background())
+
+	srf.So(err.Error(), should.Equal, "502 Bad Gateway: server error: read error")
+	srf.So(errors.Is(err, ErrServer), should.BeTrue)
+	srf.So(errors.Is(err, ErrRead), should.BeTrue)
+	srf.So(srf.fakeClient.responseBody.closed, should.Equal, 1)
+}
+
+func (srf *SearchReaderFixture) TestForbidden() {
+	srf.fakeClient.Configure([]string{`{"message": "Resource not accessible by integration"}`}, 403, nil)
+	err := srf.searchReader.Handle(context.Background())
+
+	srf.So(err.Error(), should.Equal, "Resource not accessible by integration: forbidden: read error")
+	srf.So(errors.Is(err, ErrForbidden), should.BeTrue)
+}
+
+func (srf *SearchReaderFixture) TestNotFound() {
+	srf.fakeClient.Configure([]string{`{"message": "Not Found"}`}, 404, nil)
+	err := srf.searchReader.Handle(context.Background())
+
+	srf.So(errors.Is(err, ErrNotFound), should.BeTrue)
+	srf.So(errors.Is(err, ErrRead), should.BeTrue)
+}
+
+func (srf *SearchReaderFixture) TestRateLimited() {
+	srf.fakeClient.Configure([]string{`{"message": "API rate limit exceeded"}`}, 403, nil)
+	srf.fakeClient.responseHeader = http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {"1600000000"}}
+	err := srf.searchReader.Handle(context.Background())
+
+	var rateLimitErr *RateLimitError
+	srf.So(errors.As(err, &rateLimitErr), should.BeTrue)
+	srf.So(rateLimitErr.Reset, should.Equal, time.Unix(1600000000, 0).UTC())
+	srf.So(err.Error(), should.Equal, "API rate limit exceeded (resets at 2020-09-13T12:26:40Z): rate limited: read error")
+	srf.So(errors.Is(err, ErrRateLimited), should.BeTrue)
+	srf.So(errors.Is(err, ErrRead), should.BeTrue)
+}
+
+func (srf *SearchReaderFixture) TestGraphQLRateLimited() {
+	srf.fakeClient.Configure([]string{`{"data":{"rateLimit":{"remaining":0,"resetAt":"2020-09-13T12:27:40Z"}},"errors":[{"type":"RATE_LIMITED","message":"API rate limit exceeded"}]}`}, 200, nil)
+	err := srf.searchReader.Handle(context.Background())
+
+	var rateLimitErr *RateLimitError
+	srf.So(errors.As(err, &rateLimitErr), should.BeTrue)
+	srf.So(rateLimitErr.Reset, should.Equal, time.Unix(1600000060, 0).UTC())
+	srf.So(err.Error(), should.Equal, "API rate limit exceeded (resets at 2020-09-13T12:27:40Z): rate limited: read error")
+	srf.So(errors.Is(err, ErrRead), should.BeTrue)
+}
+
+func (srf *SearchReaderFixture) TestGraphQLRateLimitedResetFromHeader() {
+	srf.fakeClient.Configure([]string{`{"data":{"rateLimit":null},"errors":[{"type":"RATE_LIMITED","message":"API rate limit exceeded"}]}`}, 200, nil)
+	srf.fakeClient.responseHeader = http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {"1600000000"}}
+	err := srf.searchReader.Handle(context.Background())
+
+	var rateLimitErr *RateLimitError
+	srf.So(errors.As(err, &rateLimitErr), should.BeTrue)
+	srf.So(rateLimitErr.Reset, should.Equal, time.Unix(1600000000, 0).UTC())
+}
+
+func (srf *SearchReaderFixture) TestForbiddenWithRetryAfterRateLimited() {
+	srf.fakeClient.Configure([]string{`{"message": "You have triggered an abuse detection mechanism."}`}, 403, nil)
+	srf.fakeClient.responseHeader = http.Header{"Retry-After": {"3600"}}
+	err := srf.searchReader.Handle(context.Background())
+
+	srf.So(errors.Is(err, ErrRateLimited), should.BeTrue)
+}
+
+func (srf *SearchReaderFixture) TestTooManyRequests() {
+	srf.fakeClient.Configure([]string{`{"message": "slow down"}`}, 429, nil)
+	err := srf.searchReader.Handle(context.Background())
+
+	srf.So(err.Error(), should.Equal, "slow down: rate limited: read error")
+	srf.So(errors.Is(err, ErrRateLimited), should.BeTrue)
+}
+
 func (srf *SearchReaderFixture) TestApiError() {
-	srf.fakeClient.Configure(responseWithError, 401, nil)
-	err := srf.searchReader.Handle()
+	srf.fakeClient.Configure(responseWithError, 200, nil)
+	err := srf.searchReader.Handle(context.Background())
 
 	srf.So(err, should.BeError)
 	srf.So(err.Error(), should.Equal, "EXCESSIVE_PAGINATION - Error 2.: EXCESSIVE_PAGINATION - Error 1.: api error: read error")
@@ -96,7 +249,7 @@ func (srf *SearchReaderFixture) TestApiError() {
 
 func (srf *SearchReaderFixture) TestReadAppError() {
 	srf.fakeClient.Configure(responseWithMessage, 401, errors.New("test error"))
-	err := srf.searchReader.Handle()
+	err := srf.searchReader.Handle(context.Background())
 
 	srf.So(err, should.BeError)
 	srf.So(err.Error(), should.Equal, "test error: read error")
@@ -104,22 +257,70 @@ func (srf *SearchReaderFixture) TestReadAppError() {
 
 func (srf *SearchReaderFixture) TestReadInvalidJsonError() {
 	srf.fakeClient.Configure(responseInvalidJson, 200, nil)
-	err := srf.searchReader.Handle()
+	err := srf.searchReader.Handle(context.Background())
 
 	srf.So(err, should.BeError)
 	srf.So(err.Error(), should.Equal, "invalid character 'e' in literal true (expecting 'r'): read error")
 }
 
+func (srf *SearchReaderFixture) TestRequestCarriesContext() {
+	ctx := context.WithValue(context.Background(), contextKey("key"), "value")
+	srf.fakeClient.Configure(responseBody, 200, nil)
+	srf.searchReader.Handle(ctx)
+
+	srf.So(srf.fakeClient.request.Context().Value(contextKey("key")), should.Equal, "value")
+}
+
+func (srf *SearchReaderFixture) TestCancelledBeforeRead() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	srf.fakeClient.Configure(responseBody, 200, nil)
+	err := srf.searchReader.Handle(ctx)
+
+	srf.So(errors.Is(err, context.Canceled), should.BeTrue)
+	srf.So(srf.fakeClient.callNr, should.Equal, 0)
+	_, open := <-srf.output
+	srf.So(open, should.BeFalse)
+}
+
+func (srf *SearchReaderFixture) TestCancelledDuringRead() {
+	ctx, cancel := context.WithCancel(context.Background())
+	srf.searchReader.total = 2
+	srf.fakeClient.Configure(responseBody, 200, nil)
+	srf.fakeClient.onRequest = cancel
+	err := srf.searchReader.Handle(ctx)
+
+	srf.So(errors.Is(err, context.Canceled), should.BeTrue)
+	srf.So(srf.fakeClient.callNr, should.Equal, 1)
+	srf.So(srf.fakeClient.responseBody.closed, should.Equal, 1)
+	srf.So(srf.searchReader.Cursor(), should.Equal, "")
+}
+
+func (srf *SearchReaderFixture) TestCancelledWhileOutputFull() {
+	ctx, cancel := context.WithCancel(context.Background())
+	srf.output = make(chan *Repository)
+	srf.searchReader = NewRepositoryReader("test:test test", 1, srf.output, srf.fakeClient)
+	srf.fakeClient.Configure(responseBody, 200, nil)
+	srf.fakeClient.onRequest = func() { time.AfterFunc(10*time.Millisecond, cancel) }
+	err := srf.searchReader.Handle(ctx)
+
+	srf.So(errors.Is(err, context.Canceled), should.BeTrue)
+}
+
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
+type contextKey string
+
 type FakeHTTPClient struct {
 	request            *http.Request
 	response           *http.Response
 	responseBody       *SearchReaderBuffer
 	responseContent    []string
 	responseStatusCode int
+	responseHeader     http.Header
 	err                error
 	callNr             int
+	onRequest          func()
 }
 
 func (fc *FakeHTTPClient) Configure(responseContent []string, statusCode int, err error) {
@@ -134,6 +335,10 @@ func (fc *FakeHTTPClient) Do(request *http.Request) (*http.Response, error) {
 
 	fc.request = request
 
+	if nil != fc.onRequest {
+		fc.onRequest()
+	}
+
 	if nil != fc.err {
 		return fc.response, fc.err
 	}
@@ -142,6 +347,7 @@ func (fc *FakeHTTPClient) Do(request *http.Request) (*http.Response, error) {
 	fc.response = &http.Response{
 		Body:       fc.responseBody,
 		StatusCode: fc.responseStatusCode,
+		Header:     fc.responseHeader,
 	}
 	fc.callNr++
 
@@ -170,15 +376,13 @@ func getResponseRepository(index int) *Repository {
 	created, _ := time.Parse(time.RFC3339, "2015-05-23T21:24:16Z")
 	updated, _ := time.Parse(time.RFC3339, "2020-04-15T20:01:25Z")
 
-	return &Repository{
+	repository := &Repository{
+		ID:            fmt.Sprintf("R_%d", index),
 		Description:   fmt.Sprintf("%d Test description.", index),
 		Name:          fmt.Sprintf("%dtestrepo", index),
 		NameWithOwner: fmt.Sprintf("%dtestrepo/testrepo", index),
 		URL:           "https://github.com/testrepo/testrepo",
-		Owner: struct {
-			Login string `json:"login"`
-		}{Login: "testrepo"},
-		ForkCount: 10,
+		ForkCount:     10,
 		Stargazers: struct {
 			TotalCount int64 `json:"totalCount"`
 		}{TotalCount: 10},
@@ -186,45 +390,67 @@ func getResponseRepository(index int) *Repository {
 			TotalCount int64 `json:"totalCount"`
 		}{TotalCount: 10},
 		HomepageURL: "testhomepage",
-		LicenseInfo: struct {
-			Name string `json:"name"`
-		}{Name: "testlicense"},
+		LicenseInfo: &License{Name: "testlicense", SpdxID: "MIT"},
 		MentionableUsers: struct {
 			TotalCount int64 `json:"totalCount"`
 		}{TotalCount: 10},
-		MirrorURL: "testmirror",
-		IsMirror:  true,
-		PrimaryLanguage: struct {
-			Name string `json:"name"`
-		}{Name: "Go"},
-		Parent: struct {
-			Name string `json:"name"`
-		}{Name: "testparent"},
-		CreatedAt: created,
-		UpdatedAt: updated,
+		MirrorURL:       "testmirror",
+		IsMirror:        true,
+		PrimaryLanguage: &Language{Name: "Go"},
+		CreatedAt:       created,
+		UpdatedAt:       updated,
 	}
+	repository.Owner.Login = "testrepo"
+	repository.Owner.Typename = "Organization"
+	repository.Owner.URL = "https://github.com/testrepo"
+	repository.Owner.IsVerified = true
+	repository.Parent = &ParentRepository{Name: "testparent", NameWithOwner: "testowner/testparent", URL: "https://github.com/testowner/testparent"}
+	repository.Parent.Stargazers.TotalCount = 500
+	repository.PushedAt, _ = time.Parse(time.RFC3339, "2020-04-16T10:00:00Z")
+	json.Unmarshal([]byte(`{"repositoryTopics": {"nodes": [{"topic": {"name": "orm"}}, {"topic": {"name": "go"}}]}}`), repository)
+	repository.IsArchived = true
+	repository.IsFork = true
+	repository.DiskUsage = 2048
+	repository.DefaultBranchRef.Name = "main"
+	repository.OpenIssues.TotalCount = 7
+	repository.OpenPullRequests.TotalCount = 3
+	repository.LatestRelease.TagName = "v1.2.0"
+	repository.LatestRelease.PublishedAt, _ = time.Parse(time.RFC3339, "2020-03-01T08:00:00Z")
+
+	return repository
 }
 
 //////////
 
-const grapqlQuery1Result = "{\"query\":\"query SearchRepositories {\\n  search(query: \\\"test:test test\\\", type: REPOSITORY, first:1){\\n    repositoryCount\\n    edges {\\n      cursor \\n      node {\\n\\t\\t\\t\\t... on Repository {\\n          description\\n          name\\n          nameWithOwner\\n          url\\n          owner {\\n            login\\n          }\\n          forkCount\\n          stargazers {\\n            totalCount\\n          }\\n          watchers {\\n            totalCount\\n          }\\n          homepageUrl\\n          licenseInfo {\\n            name\\n          }\\n          mentionableUsers {\\n            totalCount\\n          }\\n          mirrorUrl\\n          isMirror\\n          primaryLanguage {\\n            name\\n          }\\n          parent {\\n            name\\n          }\\n          createdAt\\n          updatedAt\\n        }\\n      }\\n    }\\n  }\\n}\\n\",\"variables\":{}}"
-const grapqlQuery2Result = "{\"query\":\"query SearchRepositories {\\n  search(query: \\\"test:test test\\\", type: REPOSITORY, first:1, after: \\\"aaa\\\"){\\n    repositoryCount\\n    edges {\\n      cursor \\n      node {\\n\\t\\t\\t\\t... on Repository {\\n          description\\n          name\\n          nameWithOwner\\n          url\\n          owner {\\n            login\\n          }\\n          forkCount\\n          stargazers {\\n            totalCount\\n          }\\n          watchers {\\n            totalCount\\n          }\\n          homepageUrl\\n          licenseInfo {\\n            name\\n          }\\n          mentionableUsers {\\n            totalCount\\n          }\\n          mirrorUrl\\n          isMirror\\n          primaryLanguage {\\n            name\\n          }\\n          parent {\\n            name\\n          }\\n          createdAt\\n          updatedAt\\n        }\\n      }\\n    }\\n  }\\n}\\n\",\"variables\":{}}"
+func graphQLBody(variables string) string {
+	query, _ := json.Marshal(searchQuery(DefaultFields()))
+
+	return fmt.Sprintf(`{"query":%s,"variables":%s}`, query, variables)
+}
 
 var responseBody = []string{
 	`{
     "data": {
         "search": {
             "repositoryCount": 128,
+            "pageInfo": {
+                "hasNextPage": true,
+                "endCursor": "aaa"
+            },
             "edges": [
                 {
 					"cursor": "aaa",
                     "node": {
+                        "id": "R_1",
                         "description": "1 Test description.",
                         "name": "1testrepo",
                         "nameWithOwner": "1testrepo/testrepo",
                         "url": "https://github.com/testrepo/testrepo",
                         "owner": {
-                            "login": "testrepo"
+                            "__typename": "Organization",
+                            "login": "testrepo",
+                            "url": "https://github.com/testrepo",
+                            "isVerified": true
                         },
                         "forkCount": 10,
                         "stargazers": {
@@ -235,7 +461,8 @@ var responseBody = []string{
                         },
                         "homepageUrl": "testhomepage",
                         "licenseInfo": {
-                            "name": "testlicense"
+                            "name": "testlicense",
+                            "spdxId": "MIT"
                         },
                         "mentionableUsers": {
                             "totalCount": 10
@@ -246,10 +473,40 @@ var responseBody = []string{
                             "name": "Go"
                         },
                         "parent": {
-                            "name": "testparent"
-						},
+                            "name": "testparent",
+                            "nameWithOwner": "testowner/testparent",
+                            "url": "https://github.com/testowner/testparent",
+                            "stargazers": {
+                                "totalCount": 500
+                            }
+                        },
                         "createdAt": "2015-05-23T21:24:16Z",
-                        "updatedAt": "2020-04-15T20:01:25Z"
+                        "updatedAt": "2020-04-15T20:01:25Z",
+                        "pushedAt": "2020-04-16T10:00:00Z",
+                        "repositoryTopics": {
+                            "nodes": [
+                                {"topic": {"name": "orm"}},
+                                {"topic": {"name": "go"}}
+                            ]
+                        },
+                        "isArchived": true,
+                        "isFork": true,
+                        "isTemplate": false,
+                        "isDisabled": false,
+                        "diskUsage": 2048,
+                        "defaultBranchRef": {
+                            "name": "main"
+                        },
+                        "openIssues": {
+                            "totalCount": 7
+                        },
+                        "openPullRequests": {
+                            "totalCount": 3
+                        },
+                        "latestRelease": {
+                            "tagName": "v1.2.0",
+                            "publishedAt": "2020-03-01T08:00:00Z"
+                        }
                     }
                 }
             ]
@@ -260,16 +517,24 @@ var responseBody = []string{
     "data": {
         "search": {
             "repositoryCount": 128,
+            "pageInfo": {
+                "hasNextPage": true,
+                "endCursor": "bbb"
+            },
             "edges": [
                 {
 					"cursor": "bbb",
                     "node": {
+                        "id": "R_2",
                         "description": "2 Test description.",
                         "name": "2testrepo",
                         "nameWithOwner": "2testrepo/testrepo",
                         "url": "https://github.com/testrepo/testrepo",
                         "owner": {
-                            "login": "testrepo"
+                            "__typename": "Organization",
+                            "login": "testrepo",
+                            "url": "https://github.com/testrepo",
+                            "isVerified": true
                         },
                         "forkCount": 10,
                         "stargazers": {
@@ -280,7 +545,8 @@ var responseBody = []string{
                         },
                         "homepageUrl": "testhomepage",
                         "licenseInfo": {
-                            "name": "testlicense"
+                            "name": "testlicense",
+                            "spdxId": "MIT"
                         },
                         "mentionableUsers": {
                             "totalCount": 10
@@ -291,10 +557,40 @@ var responseBody = []string{
                             "name": "Go"
                         },
                         "parent": {
-                            "name": "testparent"
-						},
+                            "name": "testparent",
+                            "nameWithOwner": "testowner/testparent",
+                            "url": "https://github.com/testowner/testparent",
+                            "stargazers": {
+                                "totalCount": 500
+                            }
+                        },
                         "createdAt": "2015-05-23T21:24:16Z",
-                        "updatedAt": "2020-04-15T20:01:25Z"
+                        "updatedAt": "2020-04-15T20:01:25Z",
+                        "pushedAt": "2020-04-16T10:00:00Z",
+                        "repositoryTopics": {
+                            "nodes": [
+                                {"topic": {"name": "orm"}},
+                                {"topic": {"name": "go"}}
+                            ]
+                        },
+                        "isArchived": true,
+                        "isFork": true,
+                        "isTemplate": false,
+                        "isDisabled": false,
+                        "diskUsage": 2048,
+                        "defaultBranchRef": {
+                            "name": "main"
+                        },
+                        "openIssues": {
+                            "totalCount": 7
+                        },
+                        "openPullRequests": {
+                            "totalCount": 3
+                        },
+                        "latestRelease": {
+                            "tagName": "v1.2.0",
+                            "publishedAt": "2020-03-01T08:00:00Z"
+                        }
                     }
                 }
             ]
@@ -304,6 +600,25 @@ var responseBody = []string{
 	`{}`,
 }
 
+var responseLastPage = []string{strings.Replace(
+	strings.Replace(responseBody[1], `"hasNextPage": true`, `"hasNextPage": false`, 1),
+	`"endCursor": "bbb"`, `"endCursor": null`, 1),
+}
+
+var responseEmptyPage = []string{`{
+    "data": {
+        "search": {
+            "repositoryCount": 128,
+            "pageInfo": {
+                "hasNextPage": true,
+                "endCursor": "ccc"
+            },
+            "edges": []
+        }
+    }
+}`,
+}
+
 var responseWithMessage = []string{`{
     "message": "Bad credentials",
     "documentation_url": "https://developer.github.com/v4"
@@ -342,6 +657,12 @@ var responseWithError = []string{`{
 }`,
 }
 
+var responseHTMLPage = []string{`<html>
+<head><title>502 Bad Gateway</title></head>
+<body><h1>502 Bad Gateway</h1></body>
+</html>`,
+}
+
 var responseInvalidJson = []string{
 	"test123",
 	"test456",
@@ -0,0 +1,158 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+// ReleaseCadence describes how regularly a repository publishes releases. The
+// dates are read from the latest 100 releases.
+type ReleaseCadence struct {
+	Total           int64
+	LatestStableTag string
+	LatestStableAt  time.Time
+	LastReleaseAt   time.Time
+	MedianInterval  time.Duration
+	Published       int
+}
+
+// NewReleasesEnricher returns the enricher computing the release cadence up to
+// now.
+func NewReleasesEnricher(now time.Time) *ReleasesEnricher {
+	re := &ReleasesEnricher{now: now}
+	re.fields = []*Field{
+		{"Releases", "", func(r *Repository) string { return strconv.FormatInt(r.ReleaseCadence.Total, 10) }, noReleaseCadence},
+		{"LatestStableRelease", "", func(r *Repository) string { return r.ReleaseCadence.LatestStableTag }, noReleaseCadence},
+		{"LatestStableReleaseDate", "", func(r *Repository) string { return formatTime(r.ReleaseCadence.LatestStableAt) }, noReleaseCadence},
+		{"DaysSinceLastRelease", "", re.daysSinceLastRelease, noReleaseCadence},
+		{"MedianReleaseIntervalDays", "", medianReleaseInterval, noReleaseCadence},
+	}
+
+	return re
+}
+
+// ReleasesEnricher fetches the releases of a repository, the latest first.
+// Drafts, which have no publication date, are left out of the cadence.
+type ReleasesEnricher struct {
+	now    time.Time
+	fields []*Field
+}
+
+func (re *ReleasesEnricher) Name() string {
+	return "releases"
+}
+
+func (re *ReleasesEnricher) Fields() []*Field {
+	return re.fields
+}
+
+func (re *ReleasesEnricher) Selection() string {
+	return "releaseHistory: releases(first: 100, orderBy: {field: CREATED_AT, direction: DESC}) { totalCount nodes { tagName publishedAt isPrerelease isDraft } }"
+}
+
+func (re *ReleasesEnricher) Enrich(repository *Repository, node json.RawMessage) error {
+	result := &struct {
+		ReleaseHistory struct {
+			TotalCount int64 `json:"totalCount"`
+			Nodes      []struct {
+				TagName      string    `json:"tagName"`
+				PublishedAt  time.Time `json:"publishedAt"`
+				IsPrerelease bool      `json:"isPrerelease"`
+				IsDraft      bool      `json:"isDraft"`
+			} `json:"nodes"`
+		} `json:"releaseHistory"`
+	}{}
+
+	if err := json.Unmarshal(node, result); nil != err {
+		return fmt.Errorf("releases of %s, %s: %w", repository.NameWithOwner, err.Error(), ErrRead)
+	}
+
+	cadence := &ReleaseCadence{Total: result.ReleaseHistory.TotalCount}
+
+	var published []time.Time
+
+	for _, release := range result.ReleaseHistory.Nodes {
+		if release.IsDraft || release.PublishedAt.IsZero() {
+			continue
+		}
+
+		published = append(published, release.PublishedAt)
+
+		if release.PublishedAt.After(cadence.LastReleaseAt) {
+			cadence.LastReleaseAt = release.PublishedAt
+		}
+
+		if !release.IsPrerelease && release.PublishedAt.After(cadence.LatestStableAt) {
+			cadence.LatestStableTag = release.TagName
+			cadence.LatestStableAt = release.PublishedAt
+		}
+	}
+
+	cadence.Published = len(published)
+	cadence.MedianInterval = medianInterval(published)
+	repository.ReleaseCadence = cadence
+
+	return nil
+}
+
+func (re *ReleasesEnricher) daysSinceLastRelease(repository *Repository) string {
+	if repository.ReleaseCadence.LastReleaseAt.IsZero() {
+		return ""
+	}
+
+	return strconv.Itoa(int(re.now.Sub(repository.ReleaseCadence.LastReleaseAt) / day))
+}
+
+// medianReleaseInterval writes the median interval in days, empty when less than
+// two releases were published.
+func medianReleaseInterval(repository *Repository) string {
+	if repository.ReleaseCadence.Published < 2 {
+		return ""
+	}
+
+	return strconv.FormatFloat(repository.ReleaseCadence.MedianInterval.Hours()/day.Hours(), 'f', 1, 64)
+}
+
+// medianInterval returns the median time between consecutive dates, 0 for less
+// than two dates.
+func medianInterval(dates []time.Time) time.Duration {
+	if len(dates) < 2 {
+		return 0
+	}
+
+	sorted := append([]time.Time{}, dates...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+
+	intervals := make([]time.Duration, len(sorted)-1)
+	for i := 1; i < len(sorted); i++ {
+		intervals[i-1] = sorted[i].Sub(sorted[i-1])
+	}
+
+	return median(intervals)
+}
+
+// median returns the median of the durations, 0 when there are none.
+func median(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	sorted := append([]time.Duration{}, durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+
+	return sorted[middle]
+}
+
+func noReleaseCadence(repository *Repository) bool {
+	return nil == repository.ReleaseCadence
+}
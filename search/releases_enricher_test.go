@@ -0,0 +1,81 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/smartystreets/gunit"
+)
+
+func TestReleasesEnricherFixture(t *testing.T) {
+	gunit.Run(new(ReleasesEnricherFixture), t)
+}
+
+type ReleasesEnricherFixture struct {
+	*gunit.Fixture
+
+	enricher   *ReleasesEnricher
+	repository *Repository
+}
+
+func (ref *ReleasesEnricherFixture) Setup() {
+	ref.enricher = NewReleasesEnricher(time.Date(2021, 4, 1, 12, 0, 0, 0, time.UTC))
+	ref.repository = &Repository{NameWithOwner: "owner/cli"}
+}
+
+func (ref *ReleasesEnricherFixture) TestCadenceComputed() {
+	err := ref.enricher.Enrich(ref.repository, []byte(releasesNode))
+
+	ref.So(err, should.BeNil)
+	ref.So(ref.repository.ReleaseCadence, should.Resemble, &ReleaseCadence{
+		Total:           5,
+		LatestStableTag: "v1.1.0",
+		LatestStableAt:  time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC),
+		LastReleaseAt:   time.Date(2021, 3, 20, 12, 0, 0, 0, time.UTC),
+		MedianInterval:  24 * day,
+		Published:       4,
+	})
+	ref.So(fieldValues(ref.enricher, ref.repository), should.Resemble, []string{"5", "v1.1.0", "2021-03-01 12:00:00 +0000 UTC", "12", "24.0"})
+}
+
+func (ref *ReleasesEnricherFixture) TestMedianOfEvenIntervals() {
+	dates := []time.Time{
+		time.Date(2021, 1, 11, 0, 0, 0, 0, time.UTC),
+		time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+
+	ref.So(medianInterval(dates), should.Equal, 5*day)
+	ref.So(medianInterval(dates[:1]), should.Equal, 0)
+}
+
+func (ref *ReleasesEnricherFixture) TestRepositoryWithoutReleases() {
+	err := ref.enricher.Enrich(ref.repository, []byte(`{"releaseHistory": {"totalCount": 0, "nodes": []}}`))
+
+	ref.So(err, should.BeNil)
+	ref.So(fieldValues(ref.enricher, ref.repository), should.Resemble, []string{"0", "", "", "", ""})
+	ref.So(ref.enricher.Fields()[0].null(ref.repository), should.BeFalse)
+}
+
+func (ref *ReleasesEnricherFixture) TestSingleReleaseHasNoInterval() {
+	ref.enricher.Enrich(ref.repository, []byte(`{"releaseHistory": {"totalCount": 1, "nodes": [
+		{"tagName": "v0.1.0", "publishedAt": "2021-03-31T12:00:00Z", "isPrerelease": true, "isDraft": false}
+	]}}`))
+
+	ref.So(fieldValues(ref.enricher, ref.repository), should.Resemble, []string{"1", "", "", "1", ""})
+}
+
+const releasesNode = `{
+    "releaseHistory": {
+        "totalCount": 5,
+        "nodes": [
+            {"tagName": "v2.0.0", "publishedAt": null, "isPrerelease": false, "isDraft": true},
+            {"tagName": "v2.0.0-rc1", "publishedAt": "2021-03-20T12:00:00Z", "isPrerelease": true, "isDraft": false},
+            {"tagName": "v1.1.0", "publishedAt": "2021-03-01T12:00:00Z", "isPrerelease": false, "isDraft": false},
+            {"tagName": "v1.0.0", "publishedAt": "2021-02-05T12:00:00Z", "isPrerelease": false, "isDraft": false},
+            {"tagName": "v0.9.0", "publishedAt": "2021-01-01T12:00:00Z", "isPrerelease": false, "isDraft": false}
+        ]
+    }
+}`
@@ -0,0 +1,194 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// MaxSampleSize is the most issues, and pull requests, sampled per repository:
+// the size of one page.
+const MaxSampleSize = 100
+
+// DefaultSampleSize is the number of issues, and pull requests, sampled per
+// repository by default.
+const DefaultSampleSize = 20
+
+// Responsiveness describes how maintainers treat issues and pull requests. The
+// open and closed counts cover all of them, the medians the latest sampled ones.
+type Responsiveness struct {
+	Open                int64
+	Closed              int64
+	MedianFirstResponse time.Duration
+	Responded           int
+	MedianClose         time.Duration
+	ClosedSampled       int
+}
+
+// NewResponsivenessEnricher returns the enricher sampling the latest sampleSize
+// issues and pull requests, at most MaxSampleSize of each.
+func NewResponsivenessEnricher(sampleSize int) *ResponsivenessEnricher {
+	if sampleSize < 1 || sampleSize > MaxSampleSize {
+		sampleSize = DefaultSampleSize
+	}
+
+	re := &ResponsivenessEnricher{sampleSize: sampleSize}
+	re.fields = []*Field{
+		{"OpenClosedRatio", "", openClosedRatio, noResponsiveness},
+		{"MedianFirstResponseHours", "", medianFirstResponse, noResponsiveness},
+		{"MedianCloseDays", "", medianClose, noResponsiveness},
+	}
+
+	return re
+}
+
+// ResponsivenessEnricher fetches the issue and pull request counts and samples
+// the latest issues and pull requests. The first response to an issue or pull
+// request is the first comment, or review, of someone else than its author.
+type ResponsivenessEnricher struct {
+	sampleSize int
+	fields     []*Field
+}
+
+func (re *ResponsivenessEnricher) Name() string {
+	return "responsiveness"
+}
+
+func (re *ResponsivenessEnricher) Fields() []*Field {
+	return re.fields
+}
+
+func (re *ResponsivenessEnricher) Selection() string {
+	return fmt.Sprintf(
+		"openIssueCount: issues(states: OPEN) { totalCount } "+
+			"closedIssueCount: issues(states: CLOSED) { totalCount } "+
+			"openPullRequestCount: pullRequests(states: OPEN) { totalCount } "+
+			"closedPullRequestCount: pullRequests(states: [CLOSED, MERGED]) { totalCount } "+
+			"issueSample: issues(first: %d, orderBy: {field: CREATED_AT, direction: DESC}) { nodes { createdAt closedAt author { login } "+
+			"comments(first: 10) { nodes { createdAt author { login } } } } } "+
+			"pullRequestSample: pullRequests(first: %d, orderBy: {field: CREATED_AT, direction: DESC}) { nodes { createdAt closedAt author { login } "+
+			"comments(first: 10) { nodes { createdAt author { login } } } "+
+			"reviews(first: 10) { nodes { createdAt author { login } } } } }",
+		re.sampleSize, re.sampleSize,
+	)
+}
+
+func (re *ResponsivenessEnricher) Enrich(repository *Repository, node json.RawMessage) error {
+	result := &struct {
+		OpenIssueCount         totalCount `json:"openIssueCount"`
+		ClosedIssueCount       totalCount `json:"closedIssueCount"`
+		OpenPullRequestCount   totalCount `json:"openPullRequestCount"`
+		ClosedPullRequestCount totalCount `json:"closedPullRequestCount"`
+		IssueSample            struct {
+			Nodes []*sampledItem `json:"nodes"`
+		} `json:"issueSample"`
+		PullRequestSample struct {
+			Nodes []*sampledItem `json:"nodes"`
+		} `json:"pullRequestSample"`
+	}{}
+
+	if err := json.Unmarshal(node, result); nil != err {
+		return fmt.Errorf("responsiveness of %s, %s: %w", repository.NameWithOwner, err.Error(), ErrRead)
+	}
+
+	responsiveness := &Responsiveness{
+		Open:   result.OpenIssueCount.TotalCount + result.OpenPullRequestCount.TotalCount,
+		Closed: result.ClosedIssueCount.TotalCount + result.ClosedPullRequestCount.TotalCount,
+	}
+
+	var responses, closes []time.Duration
+
+	for _, item := range append(result.IssueSample.Nodes, result.PullRequestSample.Nodes...) {
+		if response, ok := item.firstResponse(); ok {
+			responses = append(responses, response)
+		}
+
+		if nil != item.ClosedAt {
+			closes = append(closes, item.ClosedAt.Sub(item.CreatedAt))
+		}
+	}
+
+	responsiveness.Responded = len(responses)
+	responsiveness.MedianFirstResponse = median(responses)
+	responsiveness.ClosedSampled = len(closes)
+	responsiveness.MedianClose = median(closes)
+	repository.Responsiveness = responsiveness
+
+	return nil
+}
+
+type sampledItem struct {
+	CreatedAt time.Time      `json:"createdAt"`
+	ClosedAt  *time.Time     `json:"closedAt"`
+	Author    *sampledAuthor `json:"author"`
+	Comments  struct {
+		Nodes []*sampledResponse `json:"nodes"`
+	} `json:"comments"`
+	Reviews struct {
+		Nodes []*sampledResponse `json:"nodes"`
+	} `json:"reviews"`
+}
+
+type sampledResponse struct {
+	CreatedAt time.Time      `json:"createdAt"`
+	Author    *sampledAuthor `json:"author"`
+}
+
+// sampledAuthor is the author of an issue, a pull request or a comment, nil for a
+// deleted account.
+type sampledAuthor struct {
+	Login string `json:"login"`
+}
+
+// firstResponse returns the time from the creation of the item to the first
+// comment or review of someone else than the author.
+func (si *sampledItem) firstResponse() (time.Duration, bool) {
+	var first *time.Time
+
+	for _, response := range append(si.Comments.Nodes, si.Reviews.Nodes...) {
+		if nil != si.Author && nil != response.Author && si.Author.Login == response.Author.Login {
+			continue
+		}
+
+		if nil == first || response.CreatedAt.Before(*first) {
+			first = &response.CreatedAt
+		}
+	}
+
+	if nil == first {
+		return 0, false
+	}
+
+	return first.Sub(si.CreatedAt), true
+}
+
+// openClosedRatio writes the open issues and pull requests per closed one, empty
+// when none is closed.
+func openClosedRatio(repository *Repository) string {
+	if repository.Responsiveness.Closed == 0 {
+		return ""
+	}
+
+	return strconv.FormatFloat(float64(repository.Responsiveness.Open)/float64(repository.Responsiveness.Closed), 'f', 2, 64)
+}
+
+func medianFirstResponse(repository *Repository) string {
+	if repository.Responsiveness.Responded == 0 {
+		return ""
+	}
+
+	return strconv.FormatFloat(repository.Responsiveness.MedianFirstResponse.Hours(), 'f', 1, 64)
+}
+
+func medianClose(repository *Repository) string {
+	if repository.Responsiveness.ClosedSampled == 0 {
+		return ""
+	}
+
+	return strconv.FormatFloat(repository.Responsiveness.MedianClose.Hours()/day.Hours(), 'f', 1, 64)
+}
+
+func noResponsiveness(repository *Repository) bool {
+	return nil == repository.Responsiveness
+}
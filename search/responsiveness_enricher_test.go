@@ -0,0 +1,101 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/smartystreets/gunit"
+)
+
+func TestResponsivenessEnricherFixture(t *testing.T) {
+	gunit.Run(new(ResponsivenessEnricherFixture), t)
+}
+
+type ResponsivenessEnricherFixture struct {
+	*gunit.Fixture
+
+	enricher   *ResponsivenessEnricher
+	repository *Repository
+}
+
+func (ref *ResponsivenessEnricherFixture) Setup() {
+	ref.enricher = NewResponsivenessEnricher(5)
+	ref.repository = &Repository{NameWithOwner: "owner/cli"}
+}
+
+func (ref *ResponsivenessEnricherFixture) TestSelectionSamplesLatestItems() {
+	selection := ref.enricher.Selection()
+
+	ref.So(selection, should.ContainSubstring, "issueSample: issues(first: 5, orderBy: {field: CREATED_AT, direction: DESC})")
+	ref.So(selection, should.ContainSubstring, "pullRequestSample: pullRequests(first: 5, orderBy: {field: CREATED_AT, direction: DESC})")
+	ref.So(selection, should.ContainSubstring, "closedPullRequestCount: pullRequests(states: [CLOSED, MERGED]) { totalCount }")
+	ref.So(NewResponsivenessEnricher(MaxSampleSize+1).Selection(), should.ContainSubstring, "issues(first: 20,")
+}
+
+func (ref *ResponsivenessEnricherFixture) TestResponsivenessComputed() {
+	err := ref.enricher.Enrich(ref.repository, []byte(responsivenessNode))
+
+	ref.So(err, should.BeNil)
+	ref.So(ref.repository.Responsiveness, should.Resemble, &Responsiveness{
+		Open:                15,
+		Closed:              60,
+		MedianFirstResponse: 4 * time.Hour,
+		Responded:           3,
+		MedianClose:         3 * day,
+		ClosedSampled:       2,
+	})
+	ref.So(fieldValues(ref.enricher, ref.repository), should.Resemble, []string{"0.25", "4.0", "3.0"})
+}
+
+func (ref *ResponsivenessEnricherFixture) TestRepositoryWithoutIssues() {
+	err := ref.enricher.Enrich(ref.repository, []byte(`{
+		"openIssueCount": {"totalCount": 0}, "closedIssueCount": {"totalCount": 0},
+		"openPullRequestCount": {"totalCount": 0}, "closedPullRequestCount": {"totalCount": 0},
+		"issueSample": {"nodes": []}, "pullRequestSample": {"nodes": []}
+	}`))
+
+	ref.So(err, should.BeNil)
+	ref.So(fieldValues(ref.enricher, ref.repository), should.Resemble, []string{"", "", ""})
+	ref.So(ref.enricher.Fields()[0].null(ref.repository), should.BeFalse)
+}
+
+// The issues are answered after 2 hours (the author's own comment does not count)
+// and 10 hours, the pull request is reviewed after 4 hours and the second one is
+// not answered. The closed ones took 2 and 4 days.
+const responsivenessNode = `{
+    "openIssueCount": {"totalCount": 10},
+    "closedIssueCount": {"totalCount": 40},
+    "openPullRequestCount": {"totalCount": 5},
+    "closedPullRequestCount": {"totalCount": 20},
+    "issueSample": {
+        "nodes": [
+            {
+                "createdAt": "2021-03-01T00:00:00Z", "closedAt": "2021-03-03T00:00:00Z", "author": {"login": "ann"},
+                "comments": {"nodes": [
+                    {"createdAt": "2021-03-01T01:00:00Z", "author": {"login": "ann"}},
+                    {"createdAt": "2021-03-01T02:00:00Z", "author": {"login": "maintainer"}}
+                ]}
+            },
+            {
+                "createdAt": "2021-03-02T00:00:00Z", "closedAt": null, "author": null,
+                "comments": {"nodes": [{"createdAt": "2021-03-02T10:00:00Z", "author": {"login": "maintainer"}}]}
+            }
+        ]
+    },
+    "pullRequestSample": {
+        "nodes": [
+            {
+                "createdAt": "2021-03-05T00:00:00Z", "closedAt": "2021-03-09T00:00:00Z", "author": {"login": "bob"},
+                "comments": {"nodes": [{"createdAt": "2021-03-05T06:00:00Z", "author": {"login": "maintainer"}}]},
+                "reviews": {"nodes": [{"createdAt": "2021-03-05T04:00:00Z", "author": {"login": "maintainer"}}]}
+            },
+            {
+                "createdAt": "2021-03-06T00:00:00Z", "closedAt": null, "author": {"login": "carl"},
+                "comments": {"nodes": []},
+                "reviews": {"nodes": []}
+            }
+        ]
+    }
+}`
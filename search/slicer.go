@@ -0,0 +1,97 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+	"time"
+)
+
+// SearchResultCap is the maximum number of results github returns for a query.
+const SearchResultCap = 1000
+
+// Counter returns the number of repositories matching a query.
+type Counter interface {
+	Count(ctx context.Context, query string) (int, error)
+}
+
+// Slicer splits a query matching more repositories than the search cap into slices
+// that stay under the cap. Every slice is passed to read as soon as it is found, so
+// results stream while the slicing goes on.
+type Slicer interface {
+	Slice(ctx context.Context, query string, counter Counter, read func(query string) error) error
+}
+
+func NewDateSlicer(from time.Time, to time.Time, logger *log.Logger) *DateSlicer {
+	if nil == logger {
+		logger = log.New(ioutil.Discard, "", 0)
+	}
+
+	return &DateSlicer{from: from.UTC(), to: to.UTC(), limit: SearchResultCap, logger: logger}
+}
+
+// DateSlicer bisects the creation date range of a query with created: qualifiers.
+// The last slice has no upper bound, so it also matches the repositories created
+// after the end of the range.
+type DateSlicer struct {
+	from   time.Time
+	to     time.Time
+	limit  int
+	logger *log.Logger
+}
+
+func (ds *DateSlicer) Slice(ctx context.Context, query string, counter Counter, read func(query string) error) error {
+	count, err := counter.Count(ctx, query)
+	if nil != err {
+		return err
+	}
+
+	if count <= ds.limit {
+		return read(query)
+	}
+
+	if strings.Contains(query, "created:") {
+		ds.logger.Printf("query %q matches %d repositories but already filters on created:, not sliced", query, count)
+		return read(query)
+	}
+
+	ds.logger.Printf("query %q matches %d repositories, slicing by creation date", query, count)
+
+	return ds.bisect(ctx, query, ds.from, ds.to, counter, read)
+}
+
+func (ds *DateSlicer) bisect(ctx context.Context, query string, from time.Time, to time.Time, counter Counter, read func(query string) error) error {
+	slice := fmt.Sprintf("%s created:%s..%s", query, from.Format(time.RFC3339), to.Format(time.RFC3339))
+	if !to.Before(ds.to) {
+		slice = fmt.Sprintf("%s created:>=%s", query, from.Format(time.RFC3339))
+	}
+
+	count, err := counter.Count(ctx, slice)
+	if nil != err {
+		return err
+	}
+
+	if count == 0 {
+		return nil
+	}
+
+	if count <= ds.limit || to.Sub(from) < 2*time.Second {
+		if count > ds.limit {
+			ds.logger.Printf("slice %q matches %d repositories and can not be split, results are incomplete", slice, count)
+		} else {
+			ds.logger.Printf("slice %q: %d repositories", slice, count)
+		}
+
+		return read(slice)
+	}
+
+	middle := from.Add(to.Sub(from) / 2).Truncate(time.Second)
+
+	if err := ds.bisect(ctx, query, from, middle, counter, read); nil != err {
+		return err
+	}
+
+	return ds.bisect(ctx, query, middle.Add(time.Second), to, counter, read)
+}
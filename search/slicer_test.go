@@ -0,0 +1,302 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/smartystreets/gunit"
+
+	finderhttp "github.com/vcsfrl/github-tool-finder/http"
+)
+
+func TestDateSlicerFixture(t *testing.T) {
+	gunit.Run(new(DateSlicerFixture), t)
+}
+
+type DateSlicerFixture struct {
+	*gunit.Fixture
+
+	server *FakeSearchServer
+	output chan *Repository
+	reader *RepositoryReader
+	slicer *DateSlicer
+	logs   *bytes.Buffer
+	start  time.Time
+}
+
+func (dsf *DateSlicerFixture) Setup() {
+	dsf.start = time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	dsf.server = NewFakeSearchServer(10)
+	dsf.output = make(chan *Repository, 1000)
+	dsf.logs = &bytes.Buffer{}
+
+	endpoint, _ := finderhttp.ParseEndpoint(dsf.server.URL + "/graphql")
+	client := finderhttp.NewAuthenticationClientV4(http.DefaultClient, "", finderhttp.WithEndpoint(endpoint))
+	dsf.reader = NewRepositoryReader("language:go", 1000, dsf.output, client)
+	dsf.reader.pageSize = 4
+	dsf.slicer = NewDateSlicer(dsf.start, dsf.start.Add(100*24*time.Hour), log.New(dsf.logs, "", 0))
+	dsf.slicer.limit = 10
+	dsf.reader.UseSlicer(dsf.slicer)
+}
+
+func (dsf *DateSlicerFixture) Teardown() {
+	dsf.server.Close()
+}
+
+func (dsf *DateSlicerFixture) TestQueryUnderCapNotSliced() {
+	dsf.server.AddRepositories(dsf.start, 24*time.Hour, 8)
+
+	err := dsf.reader.Handle(context.Background())
+
+	dsf.So(err, should.BeNil)
+	dsf.So(dsf.readIDs(), should.HaveLength, 8)
+	dsf.So(dsf.server.Queries(), should.Resemble, []string{"language:go"})
+	dsf.So(dsf.logs.String(), should.BeEmpty)
+}
+
+func (dsf *DateSlicerFixture) TestQueryOverCapSliced() {
+	dsf.server.AddRepositories(dsf.start, 24*time.Hour, 45)
+
+	err := dsf.reader.Handle(context.Background())
+	ids := dsf.readIDs()
+
+	dsf.So(err, should.BeNil)
+	dsf.So(ids, should.HaveLength, 45)
+	dsf.So(dsf.unique(ids), should.HaveLength, 45)
+	dsf.So(dsf.server.Capped(), should.BeEmpty)
+	dsf.So(dsf.logs.String(), should.ContainSubstring, `query "language:go" matches 45 repositories, slicing by creation date`)
+	dsf.So(dsf.logs.String(), should.ContainSubstring, `slice "language:go created:2015-01-01T00:00:00Z..`)
+}
+
+func (dsf *DateSlicerFixture) TestSlicingStopsAtTotal() {
+	dsf.server.AddRepositories(dsf.start, 24*time.Hour, 45)
+	dsf.reader.total = 12
+
+	err := dsf.reader.Handle(context.Background())
+
+	dsf.So(err, should.BeNil)
+	dsf.So(dsf.readIDs(), should.HaveLength, 12)
+	dsf.So(dsf.server.Queries(), should.NotContain, "language:go created:>=2015-02-25T00:00:01Z")
+}
+
+func (dsf *DateSlicerFixture) TestLastSliceOpenEnded() {
+	dsf.server.AddRepositories(dsf.start, 24*time.Hour, 45)
+	dsf.server.AddRepositories(dsf.start.Add(200*24*time.Hour), time.Hour, 3)
+
+	err := dsf.reader.Handle(context.Background())
+	queries := dsf.server.Queries()
+
+	dsf.So(err, should.BeNil)
+	dsf.So(dsf.readIDs(), should.HaveLength, 48)
+	dsf.So(queries[len(queries)-1], should.StartWith, "language:go created:>=")
+}
+
+func (dsf *DateSlicerFixture) TestUnsplittableSliceLogged() {
+	dsf.slicer = NewDateSlicer(dsf.start, dsf.start.Add(time.Second), log.New(dsf.logs, "", 0))
+	dsf.slicer.limit = 10
+	dsf.reader.UseSlicer(dsf.slicer)
+	dsf.server.AddRepositories(dsf.start, 0, 15)
+
+	err := dsf.reader.Handle(context.Background())
+
+	dsf.So(err, should.BeNil)
+	dsf.So(dsf.readIDs(), should.HaveLength, 10)
+	dsf.So(dsf.logs.String(), should.ContainSubstring, "can not be split, results are incomplete")
+}
+
+func (dsf *DateSlicerFixture) TestQueryWithCreatedQualifierNotSliced() {
+	dsf.reader.query = "language:go created:>2015-01-01"
+	dsf.server.AddRepositories(dsf.start, 24*time.Hour, 15)
+
+	dsf.reader.Handle(context.Background())
+
+	dsf.So(dsf.readIDs(), should.HaveLength, 10)
+	dsf.So(dsf.logs.String(), should.ContainSubstring, "already filters on created:, not sliced")
+}
+
+func (dsf *DateSlicerFixture) readIDs() []string {
+	var ids []string
+
+	for repository := range dsf.output {
+		ids = append(ids, repository.ID)
+	}
+
+	return ids
+}
+
+func (dsf *DateSlicerFixture) unique(ids []string) map[string]bool {
+	unique := map[string]bool{}
+	for _, id := range ids {
+		unique[id] = true
+	}
+
+	return unique
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var (
+	createdRange = regexp.MustCompile(`created:(\S+)\.\.(\S+)`)
+	createdSince = regexp.MustCompile(`created:>=(\S+)`)
+	starsRange   = regexp.MustCompile(`stars:(>=|>)?(\d+)(?:\.\.(\d+))?`)
+)
+
+// FakeSearchServer answers search queries like github does: it reports the full
+// repositoryCount but never returns more than resultCap results for a query.
+type FakeSearchServer struct {
+	*httptest.Server
+
+	mutex        sync.Mutex
+	resultCap    int
+	repositories []*Repository
+	queries      []string
+	capped       []string
+}
+
+func NewFakeSearchServer(resultCap int) *FakeSearchServer {
+	fs := &FakeSearchServer{resultCap: resultCap}
+	fs.Server = httptest.NewServer(http.HandlerFunc(fs.handle))
+
+	return fs
+}
+
+func (fs *FakeSearchServer) AddRepositories(start time.Time, interval time.Duration, count int) {
+	for i := 0; i < count; i++ {
+		fs.repositories = append(fs.repositories, &Repository{
+			ID:        fmt.Sprintf("R_%d", len(fs.repositories)+1),
+			CreatedAt: start.Add(time.Duration(i) * interval),
+		})
+	}
+}
+
+func (fs *FakeSearchServer) AddStarredRepositories(created time.Time, stars ...int64) {
+	for _, count := range stars {
+		repository := &Repository{ID: fmt.Sprintf("R_%d", len(fs.repositories)+1), CreatedAt: created}
+		repository.Stargazers.TotalCount = count
+		created = created.Add(time.Hour)
+
+		fs.repositories = append(fs.repositories, repository)
+	}
+}
+
+// Queries returns the distinct search queries that were read, in order.
+func (fs *FakeSearchServer) Queries() []string {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	return fs.queries
+}
+
+// Capped returns the queries that were read past the result cap.
+func (fs *FakeSearchServer) Capped() []string {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	return fs.capped
+}
+
+func (fs *FakeSearchServer) handle(writer http.ResponseWriter, request *http.Request) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	variables := &searchVariables{}
+	json.NewDecoder(request.Body).Decode(&GraphQLRequest{Variables: variables})
+
+	query, first, offset := variables.Query, variables.First, 0
+	if nil != variables.After {
+		offset, _ = strconv.Atoi(*variables.After)
+	}
+
+	matches := fs.match(query)
+
+	fs.record(query, first, len(matches))
+
+	response := &Response{}
+	response.Data.Search.RepositoryCount = len(matches)
+
+	for i := offset; i < len(matches) && i < offset+first && i < fs.resultCap; i++ {
+		response.Data.Search.Edges = append(response.Data.Search.Edges, struct {
+			Cursor string     `json:"cursor"`
+			Node   Repository `json:"node"`
+		}{Cursor: strconv.Itoa(i + 1), Node: *matches[i]})
+	}
+
+	if end := offset + len(response.Data.Search.Edges); end < len(matches) && end < fs.resultCap {
+		response.Data.Search.PageInfo.HasNextPage = true
+		response.Data.Search.PageInfo.EndCursor = strconv.Itoa(end)
+	}
+
+	json.NewEncoder(writer).Encode(response)
+}
+
+func (fs *FakeSearchServer) record(query string, first int, count int) {
+	if first == 1 {
+		return
+	}
+
+	if len(fs.queries) == 0 || fs.queries[len(fs.queries)-1] != query {
+		fs.queries = append(fs.queries, query)
+	}
+
+	if count > fs.resultCap {
+		fs.capped = append(fs.capped, query)
+	}
+}
+
+func (fs *FakeSearchServer) match(query string) []*Repository {
+	var matches []*Repository
+
+	from, to := time.Time{}, time.Now().AddDate(100, 0, 0)
+	if bounds := createdRange.FindStringSubmatch(query); nil != bounds {
+		from, _ = time.Parse(time.RFC3339, bounds[1])
+		to, _ = time.Parse(time.RFC3339, bounds[2])
+	}
+	if bounds := createdSince.FindStringSubmatch(query); nil != bounds {
+		from, _ = time.Parse(time.RFC3339, bounds[1])
+	}
+
+	minStars, maxStars := int64(0), int64(1<<62)
+	if bounds := starsRange.FindStringSubmatch(query); nil != bounds {
+		minStars, _ = strconv.ParseInt(bounds[2], 10, 64)
+		if bounds[1] == ">" {
+			minStars++
+		}
+		switch {
+		case bounds[3] != "":
+			maxStars, _ = strconv.ParseInt(bounds[3], 10, 64)
+		case bounds[1] == "":
+			maxStars = minStars
+		}
+	}
+
+	for _, repository := range fs.repositories {
+		stars := repository.Stargazers.TotalCount
+		if repository.CreatedAt.Before(from) || repository.CreatedAt.After(to) || stars < minStars || stars > maxStars {
+			continue
+		}
+
+		matches = append(matches, repository)
+	}
+
+	if strings.Contains(query, "sort:stars-desc") {
+		sort.SliceStable(matches, func(i, j int) bool {
+			return matches[i].Stargazers.TotalCount > matches[j].Stargazers.TotalCount
+		})
+	}
+
+	return matches
+}
@@ -0,0 +1,227 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	starsQualifier = regexp.MustCompile(`(^|\s)stars:(\S+)`)
+	sortQualifier  = regexp.MustCompile(`(^|\s)sort:\S+`)
+)
+
+// NewStarSlicer returns a slicer splitting a query into star ranges. A single star
+// count matching more repositories than the cap is split further by the fallback
+// slicer, when given.
+func NewStarSlicer(fallback Slicer, logger *log.Logger) *StarSlicer {
+	if nil == logger {
+		logger = log.New(ioutil.Discard, "", 0)
+	}
+
+	return &StarSlicer{fallback: fallback, limit: SearchResultCap, logger: logger}
+}
+
+// StarSlicer splits a query into stars: ranges read from the most starred down, so
+// the repositories are read in descending star order. The range boundaries are
+// chosen with repositoryCount probes: every range is as wide as possible while
+// still under the cap, which keeps the sparse top narrow and the dense bottom
+// ranges small.
+type StarSlicer struct {
+	fallback Slicer
+	limit    int
+	logger   *log.Logger
+}
+
+func (ss *StarSlicer) Slice(ctx context.Context, query string, counter Counter, read func(query string) error) error {
+	count, err := counter.Count(ctx, query)
+	if nil != err {
+		return err
+	}
+
+	if count <= ss.limit {
+		return read(resorted(query))
+	}
+
+	base, min, max, err := parseStars(query)
+	if nil != err {
+		ss.logger.Printf("query %q matches %d repositories but %s, not sliced", query, count, err)
+		return read(resorted(query))
+	}
+
+	ss.logger.Printf("query %q matches %d repositories, slicing by stars", query, count)
+
+	return ss.slice(ctx, base, min, max, counter, read)
+}
+
+// slice reads the star ranges from max down to min. A negative max is unbounded.
+func (ss *StarSlicer) slice(ctx context.Context, base string, min int, max int, counter Counter, read func(query string) error) error {
+	for {
+		low, count, err := ss.lowestBoundary(ctx, base, min, max, counter)
+		if nil != err {
+			return err
+		}
+
+		slice := starsQuery(base, low, max)
+
+		switch {
+		case count == 0:
+		case count <= ss.limit:
+			ss.logger.Printf("slice %q: %d repositories", slice, count)
+			err = read(sorted(slice))
+		case nil != ss.fallback:
+			ss.logger.Printf("slice %q matches %d repositories, splitting it further", slice, count)
+			err = ss.fallback.Slice(ctx, sorted(slice), counter, read)
+		default:
+			ss.logger.Printf("slice %q matches %d repositories and can not be split, results are incomplete", slice, count)
+			err = read(sorted(slice))
+		}
+
+		if nil != err || low <= min {
+			return err
+		}
+
+		max = low - 1
+	}
+}
+
+// lowestBoundary finds the lowest star count low so that low..max stays under the
+// cap: it gallops upwards from min and then bisects the last step. A single star
+// count over the cap is returned with its count.
+func (ss *StarSlicer) lowestBoundary(ctx context.Context, base string, min int, max int, counter Counter) (int, int, error) {
+	count, err := counter.Count(ctx, starsQuery(base, min, max))
+	if nil != err || count <= ss.limit {
+		return min, count, err
+	}
+
+	over, step := min, 1
+	under, underCount := -1, 0
+
+	for under < 0 {
+		next := over + step
+		if max >= 0 && next >= max {
+			next = max
+		}
+
+		count, err := counter.Count(ctx, starsQuery(base, next, max))
+		if nil != err {
+			return 0, 0, err
+		}
+
+		if count > ss.limit {
+			if next == max {
+				return max, count, nil
+			}
+
+			over, step = next, step*2
+			continue
+		}
+
+		under, underCount = next, count
+	}
+
+	for under-over > 1 {
+		middle := over + (under-over)/2
+
+		count, err := counter.Count(ctx, starsQuery(base, middle, max))
+		if nil != err {
+			return 0, 0, err
+		}
+
+		if count > ss.limit {
+			over = middle
+		} else {
+			under, underCount = middle, count
+		}
+	}
+
+	return under, underCount, nil
+}
+
+// parseStars removes the stars: and sort: qualifiers from the query and returns the
+// star range they requested. A negative max is unbounded.
+func parseStars(query string) (string, int, int, error) {
+	min, max := 0, -1
+
+	qualifiers := starsQualifier.FindAllStringSubmatch(query, -1)
+	if len(qualifiers) > 1 {
+		return "", 0, 0, fmt.Errorf("it has several stars: qualifiers")
+	}
+
+	if len(qualifiers) == 1 {
+		var err error
+		if min, max, err = parseStarsRange(qualifiers[0][2]); nil != err {
+			return "", 0, 0, err
+		}
+	}
+
+	base := starsQualifier.ReplaceAllString(query, " ")
+	base = sortQualifier.ReplaceAllString(base, " ")
+
+	return strings.Join(strings.Fields(base), " "), min, max, nil
+}
+
+func parseStarsRange(value string) (int, int, error) {
+	var (
+		min, max = 0, -1
+		err      error
+	)
+
+	switch {
+	case strings.HasPrefix(value, ">="):
+		min, err = strconv.Atoi(value[2:])
+	case strings.HasPrefix(value, ">"):
+		min, err = strconv.Atoi(value[1:])
+		min++
+	case strings.HasPrefix(value, "<="):
+		max, err = strconv.Atoi(value[2:])
+	case strings.HasPrefix(value, "<"):
+		if max, err = strconv.Atoi(value[1:]); max < 1 {
+			err = strconv.ErrRange
+		}
+		max--
+	case strings.Contains(value, ".."):
+		bounds := strings.SplitN(value, "..", 2)
+		if bounds[0] != "*" {
+			min, err = strconv.Atoi(bounds[0])
+		}
+		if nil == err && bounds[1] != "*" {
+			max, err = strconv.Atoi(bounds[1])
+		}
+	default:
+		min, err = strconv.Atoi(value)
+		max = min
+	}
+
+	if nil != err {
+		return 0, 0, fmt.Errorf("the stars:%s qualifier is not supported", value)
+	}
+
+	return min, max, nil
+}
+
+func starsQuery(base string, min int, max int) string {
+	if max < 0 {
+		return fmt.Sprintf("%s stars:>=%d", base, min)
+	}
+
+	if min == max {
+		return fmt.Sprintf("%s stars:%d", base, min)
+	}
+
+	return fmt.Sprintf("%s stars:%d..%d", base, min, max)
+}
+
+func sorted(query string) string {
+	return query + " sort:stars-desc"
+}
+
+// resorted replaces the sort: qualifier of the query, so a query read without
+// slicing is in descending star order too.
+func resorted(query string) string {
+	return sorted(strings.Join(strings.Fields(sortQualifier.ReplaceAllString(query, " ")), " "))
+}
@@ -0,0 +1,173 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/smartystreets/gunit"
+
+	finderhttp "github.com/vcsfrl/github-tool-finder/http"
+)
+
+func TestStarSlicerFixture(t *testing.T) {
+	gunit.Run(new(StarSlicerFixture), t)
+}
+
+type StarSlicerFixture struct {
+	*gunit.Fixture
+
+	server  *FakeSearchServer
+	output  chan *Repository
+	reader  *RepositoryReader
+	slicer  *StarSlicer
+	logs    *bytes.Buffer
+	created time.Time
+}
+
+func (ssf *StarSlicerFixture) Setup() {
+	ssf.created = time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	ssf.server = NewFakeSearchServer(10)
+	ssf.output = make(chan *Repository, 1000)
+	ssf.logs = &bytes.Buffer{}
+
+	endpoint, _ := finderhttp.ParseEndpoint(ssf.server.URL + "/graphql")
+	client := finderhttp.NewAuthenticationClientV4(http.DefaultClient, "", finderhttp.WithEndpoint(endpoint))
+	ssf.reader = NewRepositoryReader("language:go stars:>50 sort:updated", 1000, ssf.output, client)
+	ssf.reader.pageSize = 4
+	ssf.slicer = NewStarSlicer(nil, log.New(ssf.logs, "", 0))
+	ssf.slicer.limit = 10
+	ssf.reader.UseSlicer(ssf.slicer)
+}
+
+func (ssf *StarSlicerFixture) Teardown() {
+	ssf.server.Close()
+}
+
+func (ssf *StarSlicerFixture) TestQueryUnderCapNotSliced() {
+	ssf.server.AddStarredRepositories(ssf.created, 60, 70, 80)
+
+	err := ssf.reader.Handle(context.Background())
+
+	ssf.So(err, should.BeNil)
+	ssf.So(ssf.readStars(), should.Resemble, []int64{80, 70, 60})
+	ssf.So(ssf.server.Queries(), should.Resemble, []string{"language:go stars:>50 sort:stars-desc"})
+}
+
+func (ssf *StarSlicerFixture) TestQueryOverCapSlicedInDescendingStarOrder() {
+	ssf.server.AddStarredRepositories(ssf.created, 10, 20, 50, 5000, 900, 120, 51)
+	ssf.server.AddStarredRepositories(ssf.created, ssf.repeat(60, 9)...)
+	ssf.server.AddStarredRepositories(ssf.created, ssf.repeat(55, 8)...)
+	ssf.server.AddStarredRepositories(ssf.created, 52, 53, 54, 56, 57, 58, 59, 61, 300)
+
+	err := ssf.reader.Handle(context.Background())
+	stars := ssf.readStars()
+
+	ssf.So(err, should.BeNil)
+	ssf.So(stars, should.HaveLength, 30)
+	ssf.So(ssf.isDescending(stars), should.BeTrue)
+	ssf.So(stars[len(stars)-1], should.Equal, 51)
+	ssf.So(ssf.server.Capped(), should.BeEmpty)
+	ssf.So(ssf.server.Queries()[0], should.Equal, "language:go stars:>=61 sort:stars-desc")
+	ssf.So(ssf.logs.String(), should.ContainSubstring, `query "language:go stars:>50 sort:updated" matches 30 repositories, slicing by stars`)
+}
+
+func (ssf *StarSlicerFixture) TestOverflowingStarCountSplitByFallback() {
+	ssf.slicer.fallback = NewDateSlicer(ssf.created, ssf.created.Add(30*time.Hour), nil)
+	ssf.slicer.fallback.(*DateSlicer).limit = 10
+	ssf.server.AddStarredRepositories(ssf.created, 500, 400)
+	ssf.server.AddStarredRepositories(ssf.created, ssf.repeat(60, 25)...)
+
+	err := ssf.reader.Handle(context.Background())
+	stars := ssf.readStars()
+
+	ssf.So(err, should.BeNil)
+	ssf.So(stars, should.HaveLength, 27)
+	ssf.So(ssf.isDescending(stars), should.BeTrue)
+	ssf.So(ssf.server.Capped(), should.BeEmpty)
+	ssf.So(ssf.logs.String(), should.ContainSubstring, `slice "language:go stars:60" matches 25 repositories, splitting it further`)
+}
+
+func (ssf *StarSlicerFixture) TestOverflowingStarCountWithoutFallbackLogged() {
+	ssf.server.AddStarredRepositories(ssf.created, ssf.repeat(60, 15)...)
+
+	err := ssf.reader.Handle(context.Background())
+
+	ssf.So(err, should.BeNil)
+	ssf.So(ssf.readStars(), should.HaveLength, 10)
+	ssf.So(ssf.logs.String(), should.ContainSubstring, "can not be split, results are incomplete")
+}
+
+func (ssf *StarSlicerFixture) TestUnsupportedStarsQualifierNotSliced() {
+	ssf.reader.query = "language:go stars:>many"
+	ssf.server.AddStarredRepositories(ssf.created, ssf.repeat(60, 15)...)
+
+	ssf.reader.Handle(context.Background())
+
+	ssf.So(ssf.readStars(), should.HaveLength, 10)
+	ssf.So(ssf.logs.String(), should.ContainSubstring, "the stars:>many qualifier is not supported, not sliced")
+	ssf.So(ssf.server.Queries(), should.Resemble, []string{"language:go stars:>many sort:stars-desc"})
+}
+
+func (ssf *StarSlicerFixture) TestParseStars() {
+	ranges := map[string][]int{
+		"go stars:>50":      {51, -1},
+		"go stars:>=50":     {50, -1},
+		"go stars:<50":      {0, 49},
+		"go stars:<=50":     {0, 50},
+		"go stars:10..20":   {10, 20},
+		"go stars:10..*":    {10, -1},
+		"go stars:*..20":    {0, 20},
+		"go stars:7":        {7, 7},
+		"stars:7 go":        {7, 7},
+		"go sort:stars-asc": {0, -1},
+	}
+
+	for query, expected := range ranges {
+		base, min, max, err := parseStars(query)
+
+		ssf.So(err, should.BeNil)
+		ssf.So(base, should.Equal, "go")
+		ssf.So([]int{min, max}, should.Resemble, expected)
+	}
+
+	_, _, _, err := parseStars("go stars:<0")
+	ssf.So(err, should.NotBeNil)
+
+	_, _, _, err = parseStars("go stars:>1 stars:<5")
+	ssf.So(err, should.NotBeNil)
+}
+
+func (ssf *StarSlicerFixture) readStars() []int64 {
+	var stars []int64
+
+	for repository := range ssf.output {
+		stars = append(stars, repository.Stargazers.TotalCount)
+	}
+
+	return stars
+}
+
+func (ssf *StarSlicerFixture) isDescending(stars []int64) bool {
+	for i := 1; i < len(stars); i++ {
+		if stars[i] > stars[i-1] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (ssf *StarSlicerFixture) repeat(stars int64, count int) []int64 {
+	repeated := make([]int64, count)
+	for i := range repeated {
+		repeated[i] = stars
+	}
+
+	return repeated
+}
@@ -1,47 +1,79 @@
 package search
 
 import (
+	"context"
 	"encoding/csv"
-	"fmt"
 	"io"
-	"strconv"
 )
 
 type CsvWriter struct {
-	input  chan *Repository
-	closer io.Closer
-	writer *csv.Writer
+	input   chan *Repository
+	closer  io.Closer
+	writer  *csv.Writer
+	fields  []*Field
+	null    string
+	written int
 }
 
-func (cw *CsvWriter) Handle() error {
+// SetNullValue sets the text written for fields github returned as null, e.g. the
+// license of a repository without one. It is empty by default.
+func (cw *CsvWriter) SetNullValue(null string) {
+	cw.null = null
+}
+
+// SelectFields writes only the given fields, as columns in the given order.
+func (cw *CsvWriter) SelectFields(fields []*Field) {
+	cw.fields = fields
+}
+
+// Written returns the number of repositories written.
+func (cw *CsvWriter) Written() int {
+	return cw.written
+}
+
+// Handle writes the header and then the repositories until the input channel is
+// closed, then flushes the rows and closes the output. The writer is not stopped
+// by the cancellation of the context: the upstream handlers close the channel
+// when they stop, so every fetched repository is written. Once the context is
+// cancelled every row is flushed as soon as it is written.
+func (cw *CsvWriter) Handle(ctx context.Context) error {
+	cw.writeHeader()
+
 	for repository := range cw.input {
 		cw.writeRepository(repository)
+		cw.written++
+
+		if nil != ctx.Err() {
+			cw.writer.Flush()
+		}
 	}
 
 	cw.writer.Flush()
+
 	return cw.closer.Close()
 }
 
+func (cw *CsvWriter) writeHeader() {
+	values := make([]string, len(cw.fields))
+	for i, field := range cw.fields {
+		values[i] = field.Name
+	}
+
+	cw.writeValues(values...)
+}
+
 func (cw *CsvWriter) writeRepository(repository *Repository) {
-	cw.writeValues(
-		repository.Name,
-		repository.NameWithOwner,
-		repository.Owner.Login,
-		repository.Description,
-		repository.URL,
-		fmt.Sprintf("%d", repository.ForkCount),
-		fmt.Sprintf("%d", repository.Stargazers.TotalCount),
-		fmt.Sprintf("%d", repository.Watchers.TotalCount),
-		repository.HomepageURL,
-		repository.LicenseInfo.Name,
-		fmt.Sprintf("%d", repository.MentionableUsers.TotalCount),
-		repository.MirrorURL,
-		strconv.FormatBool(repository.IsMirror),
-		repository.PrimaryLanguage.Name,
-		repository.Parent.Name,
-		repository.CreatedAt.String(),
-		repository.UpdatedAt.String(),
-	)
+	values := make([]string, len(cw.fields))
+	for i, field := range cw.fields {
+		if nil != field.null && field.null(repository) {
+			values[i] = cw.null
+			continue
+		}
+
+		values[i] = field.value(repository)
+	}
+
+	cw.writeValues(values...)
 }
 
 func (cw *CsvWriter) writeValues(values ...string) {
@@ -49,13 +81,10 @@ func (cw *CsvWriter) writeValues(values ...string) {
 }
 
 func NewCsvWriter(input chan *Repository, output io.WriteCloser) *CsvWriter {
-	this := &CsvWriter{
+	return &CsvWriter{
 		input:  input,
 		closer: output,
 		writer: csv.NewWriter(output),
+		fields: DefaultFields(),
 	}
-
-	this.writeValues("Name", "NameWithOwner", "Owner", "Description", "URL", "ForkCount", "Stargazers", "Watchers", "HomepageURL", "LicenseInfo", "MentionableUsers", "MirrorURL", "IsMirror", "PrimaryLanguage", "Parent", "CreatedAt", "UpdatedAt")
-
-	return this
 }
@@ -2,6 +2,8 @@ package search
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
@@ -32,14 +34,14 @@ func (whf *WriterHandlerFixture) Setup() {
 
 func (whf *WriterHandlerFixture) TestOutputClosed() {
 	close(whf.input)
-	whf.handler.Handle()
+	whf.handler.Handle(context.Background())
 	whf.So(whf.buffer.closed, should.Equal, 1)
 }
 
 func (whf *WriterHandlerFixture) TestHeaderMatchesRecord() {
 	whf.input <- whf.createRepository(1)
 	close(whf.input)
-	whf.handler.Handle()
+	whf.handler.Handle(context.Background())
 	whf.assertHeaderMatchesRecord()
 }
 
@@ -48,20 +50,115 @@ func (whf *WriterHandlerFixture) assertHeaderMatchesRecord() {
 	header := lines[0]
 	record := lines[1]
 
-	whf.So(header, should.Equal, "Name,NameWithOwner,Owner,Description,URL,ForkCount,Stargazers,Watchers,HomepageURL,LicenseInfo,MentionableUsers,MirrorURL,IsMirror,PrimaryLanguage,Parent,CreatedAt,UpdatedAt")
-	whf.So(record, should.Equal, "Name1,NameWithOwner1,Owner1,Description1,URL1,2,3,4,HomepageURL1,LicenseInfo1,5,MirrorURL1,false,PrimaryLanguage1,Parent1,2020-04-15 20:01:25 +0000 UTC,2020-05-15 20:01:25 +0000 UTC")
+	whf.So(header, should.Equal, "Name,NameWithOwner,Owner,Description,URL,ForkCount,Stargazers,Watchers,HomepageURL,LicenseInfo,MentionableUsers,MirrorURL,IsMirror,PrimaryLanguage,Parent,CreatedAt,UpdatedAt,"+
+		"PushedAt,Topics,IsArchived,IsFork,IsTemplate,IsDisabled,DiskUsage,DefaultBranch,OpenIssues,OpenPullRequests,LatestReleaseTag,LatestReleaseDate,LicenseSpdxID,"+
+		"OwnerType,OwnerURL,OwnerVerified,ParentNameWithOwner,ParentURL,ParentStargazers")
+	whf.So(record, should.Equal, "Name1,NameWithOwner1,Owner1,Description1,URL1,2,3,4,HomepageURL1,LicenseInfo1,5,MirrorURL1,false,PrimaryLanguage1,Parent1,2020-04-15 20:01:25 +0000 UTC,2020-05-15 20:01:25 +0000 UTC,"+
+		"2020-06-15 20:01:25 +0000 UTC,topic1;go,true,false,true,false,6,main,7,8,v1.0,2020-07-15 20:01:25 +0000 UTC,MIT,"+
+		"Organization,OwnerURL1,true,ParentOwner1/Parent1,ParentURL1,9")
+}
+
+func (whf *WriterHandlerFixture) TestSelectedFieldsWritten() {
+	selected, _ := ParseFields("Stargazers,Name,IsMirror")
+	whf.handler.SelectFields(selected)
+	whf.input <- whf.createRepository(1)
+	close(whf.input)
+	whf.handler.Handle(context.Background())
+
+	lines := whf.outputLines()
+	whf.So(lines[0], should.Equal, "Stargazers,Name,IsMirror")
+	whf.So(lines[1], should.Equal, "3,Name1,false")
 }
 
 func (whf *WriterHandlerFixture) TestAllRepositoriesWritten() {
 	whf.sendEnvelopes(2)
-	whf.handler.Handle()
+	whf.handler.Handle(context.Background())
 
+	whf.So(whf.handler.Written(), should.Equal, 2)
 	if lines := whf.outputLines(); whf.So(lines, should.HaveLength, 3) {
-		whf.So(lines[1], should.Equal, "Name1,NameWithOwner1,Owner1,Description1,URL1,2,3,4,HomepageURL1,LicenseInfo1,5,MirrorURL1,false,PrimaryLanguage1,Parent1,2020-04-15 20:01:25 +0000 UTC,2020-05-15 20:01:25 +0000 UTC")
-		whf.So(lines[2], should.Equal, "Name2,NameWithOwner2,Owner2,Description2,URL2,3,4,5,HomepageURL2,LicenseInfo2,6,MirrorURL2,false,PrimaryLanguage2,Parent2,2020-04-15 20:01:25 +0000 UTC,2020-05-15 20:01:25 +0000 UTC")
+		whf.So(lines[1], should.Equal, "Name1,NameWithOwner1,Owner1,Description1,URL1,2,3,4,HomepageURL1,LicenseInfo1,5,MirrorURL1,false,PrimaryLanguage1,Parent1,2020-04-15 20:01:25 +0000 UTC,2020-05-15 20:01:25 +0000 UTC,"+
+			"2020-06-15 20:01:25 +0000 UTC,topic1;go,true,false,true,false,6,main,7,8,v1.0,2020-07-15 20:01:25 +0000 UTC,MIT,"+
+			"Organization,OwnerURL1,true,ParentOwner1/Parent1,ParentURL1,9")
+		whf.So(lines[2], should.Equal, "Name2,NameWithOwner2,Owner2,Description2,URL2,3,4,5,HomepageURL2,LicenseInfo2,6,MirrorURL2,false,PrimaryLanguage2,Parent2,2020-04-15 20:01:25 +0000 UTC,2020-05-15 20:01:25 +0000 UTC,"+
+			"2020-06-15 20:01:25 +0000 UTC,topic2;go,true,false,true,false,7,main,8,9,v2.0,2020-07-15 20:01:25 +0000 UTC,MIT,"+
+			"Organization,OwnerURL2,true,ParentOwner2/Parent2,ParentURL2,10")
 	}
 }
 
+func (whf *WriterHandlerFixture) TestMissingValuesWrittenEmpty() {
+	selected, _ := ParseFields("Name,PushedAt,Topics,DefaultBranch,LatestReleaseTag,LatestReleaseDate,LicenseSpdxID," +
+		"OwnerType,OwnerURL,OwnerVerified,ParentNameWithOwner,ParentURL,ParentStargazers")
+	whf.handler.SelectFields(selected)
+	whf.input <- &Repository{Name: "Name1"}
+	close(whf.input)
+	whf.handler.Handle(context.Background())
+
+	whf.So(whf.outputLines()[1], should.Equal, "Name1,,,,,,,,,,,,")
+}
+
+func (whf *WriterHandlerFixture) TestNullValuesWritten() {
+	selected, _ := ParseFields("Name,LicenseInfo,LicenseSpdxID,PrimaryLanguage,Parent,ParentNameWithOwner,ParentURL,ParentStargazers")
+	whf.handler.SelectFields(selected)
+	whf.handler.SetNullValue("NULL")
+	whf.input <- &Repository{Name: "Name1"}
+	whf.input <- &Repository{Name: "Name2", LicenseInfo: &License{}, PrimaryLanguage: &Language{}, Parent: &ParentRepository{}}
+	close(whf.input)
+	whf.handler.Handle(context.Background())
+
+	lines := whf.outputLines()
+	whf.So(lines[1], should.Equal, "Name1,NULL,NULL,NULL,NULL,NULL,NULL,NULL")
+	whf.So(lines[2], should.Equal, "Name2,,,,,,,0")
+}
+
+func (whf *WriterHandlerFixture) TestUserOwnerNotVerified() {
+	selected, _ := ParseFields("Owner,OwnerType,OwnerVerified")
+	whf.handler.SelectFields(selected)
+	repository := whf.createRepository(1)
+	repository.Owner.Typename = "User"
+	whf.input <- repository
+	close(whf.input)
+	whf.handler.Handle(context.Background())
+
+	whf.So(whf.outputLines()[1], should.Equal, "Owner1,User,")
+}
+
+func (whf *WriterHandlerFixture) TestOnlyOwnerVerifiedSelected() {
+	selected, _ := ParseFields("OwnerVerified")
+	whf.handler.SelectFields(selected)
+	whf.handler.SetNullValue("NULL")
+	organization, user := &Repository{}, &Repository{}
+	json.Unmarshal([]byte(`{"owner": {"__typename": "Organization", "isVerified": true}}`), organization)
+	json.Unmarshal([]byte(`{"owner": {"__typename": "User"}}`), user)
+	whf.input <- organization
+	whf.input <- user
+	close(whf.input)
+	whf.handler.Handle(context.Background())
+
+	whf.So(selectionSet(selected), should.Equal, "id\n          owner { __typename ... on Organization { isVerified } }")
+	whf.So(whf.outputLines()[1:3], should.Resemble, []string{"true", "NULL"})
+}
+
+func (whf *WriterHandlerFixture) TestCancelledWriterFlushesEveryRowAndKeepsDraining() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	whf.input <- whf.createRepository(1)
+	handled := make(chan error, 1)
+	go func() {
+		handled <- whf.handler.Handle(ctx)
+	}()
+
+	for len(whf.outputLines()) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	whf.input <- whf.createRepository(2)
+	close(whf.input)
+
+	whf.So(<-handled, should.BeNil)
+	whf.So(whf.handler.Written(), should.Equal, 2)
+	whf.So(whf.outputLines(), should.HaveLength, 3)
+	whf.So(whf.buffer.closed, should.Equal, 1)
+}
+
 func (whf *WriterHandlerFixture) sendEnvelopes(count int) {
 	for i := 1; i < count+1; i++ {
 		whf.input <- whf.createRepository(int64(i))
@@ -73,15 +170,12 @@ func (whf *WriterHandlerFixture) createRepository(index int64) *Repository {
 	created, _ := time.Parse(time.RFC3339, "2020-04-15T20:01:25Z")
 	updated, _ := time.Parse(time.RFC3339, "2020-05-15T20:01:25Z")
 
-	return &Repository{
+	repository := &Repository{
 		Description:   fmt.Sprintf("Description%d", index),
 		Name:          fmt.Sprintf("Name%d", index),
 		NameWithOwner: fmt.Sprintf("NameWithOwner%d", index),
 		URL:           fmt.Sprintf("URL%d", index),
-		Owner: struct {
-			Login string `json:"login"`
-		}{Login: fmt.Sprintf("Owner%d", index)},
-		ForkCount: index + 1,
+		ForkCount:     index + 1,
 		Stargazers: struct {
 			TotalCount int64 `json:"totalCount"`
 		}{TotalCount: index + 2},
@@ -89,23 +183,39 @@ func (whf *WriterHandlerFixture) createRepository(index int64) *Repository {
 			TotalCount int64 `json:"totalCount"`
 		}{TotalCount: index + 3},
 		HomepageURL: fmt.Sprintf("HomepageURL%d", index),
-		LicenseInfo: struct {
-			Name string `json:"name"`
-		}{Name: fmt.Sprintf("LicenseInfo%d", index)},
+		LicenseInfo: &License{Name: fmt.Sprintf("LicenseInfo%d", index), SpdxID: "MIT"},
 		MentionableUsers: struct {
 			TotalCount int64 `json:"totalCount"`
 		}{TotalCount: index + 4},
-		MirrorURL: fmt.Sprintf("MirrorURL%d", index),
-		IsMirror:  false,
-		PrimaryLanguage: struct {
-			Name string `json:"name"`
-		}{Name: fmt.Sprintf("PrimaryLanguage%d", index)},
-		Parent: struct {
-			Name string `json:"name"`
-		}{Name: fmt.Sprintf("Parent%d", index)},
-		CreatedAt: created,
-		UpdatedAt: updated,
+		MirrorURL:       fmt.Sprintf("MirrorURL%d", index),
+		IsMirror:        false,
+		PrimaryLanguage: &Language{Name: fmt.Sprintf("PrimaryLanguage%d", index)},
+		CreatedAt:       created,
+		UpdatedAt:       updated,
+	}
+
+	repository.Owner.Login = fmt.Sprintf("Owner%d", index)
+	repository.Owner.Typename = "Organization"
+	repository.Owner.URL = fmt.Sprintf("OwnerURL%d", index)
+	repository.Owner.IsVerified = true
+	repository.Parent = &ParentRepository{
+		Name:          fmt.Sprintf("Parent%d", index),
+		NameWithOwner: fmt.Sprintf("ParentOwner%d/Parent%d", index, index),
+		URL:           fmt.Sprintf("ParentURL%d", index),
 	}
+	repository.Parent.Stargazers.TotalCount = index + 8
+	repository.PushedAt, _ = time.Parse(time.RFC3339, "2020-06-15T20:01:25Z")
+	json.Unmarshal([]byte(fmt.Sprintf(`{"repositoryTopics": {"nodes": [{"topic": {"name": "topic%d"}}, {"topic": {"name": "go"}}]}}`, index)), repository)
+	repository.IsArchived = true
+	repository.IsTemplate = true
+	repository.DiskUsage = index + 5
+	repository.DefaultBranchRef.Name = "main"
+	repository.OpenIssues.TotalCount = index + 6
+	repository.OpenPullRequests.TotalCount = index + 7
+	repository.LatestRelease.TagName = fmt.Sprintf("v%d.0", index)
+	repository.LatestRelease.PublishedAt, _ = time.Parse(time.RFC3339, "2020-07-15T20:01:25Z")
+
+	return repository
 }
 
 func (whf *WriterHandlerFixture) outputLines() []string {